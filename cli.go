@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"wander/formatter"
+	"wander/nomad"
+)
+
+const usage = `Usage:
+  wander                                   start the interactive app
+  wander jobs [flags]                      list jobs
+  wander allocs [flags] [job]              list allocations of a job, or of all jobs
+  wander logs [flags] <alloc> <task>       print the end of a task's logs
+  wander keys                              list key bindings and the names to configure them by
+
+Flags:
+  -o, --output table|json|csv|tsv          output format (default table)
+  allocs -n, --namespace <namespace>       namespace of the job
+  allocs -d, --datacenter <datacenter>     only allocations in datacenter
+  logs --stderr                            stderr instead of stdout
+  logs --tail <bytes>                      bytes to print from the end of the logs
+`
+
+// errUsage is returned for invalid arguments, after usage has been printed
+var errUsage = errors.New("invalid arguments")
+
+// runCommand runs a subcommand that prints a table to stdout instead of starting the TUI, returning the exit code.
+// newClient is only called once the arguments are known to be valid.
+func runCommand(args []string, newClient func() *nomad.Client, stdout, stderr io.Writer) int {
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	if args[0] == "keys" {
+		if err := writeTable(stdout, keyBindingsTable(), "table"); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	err := runTableCommand(args, newClient, stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	}
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	return 1
+}
+
+func runTableCommand(args []string, newClient func() *nomad.Client, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("wander "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	var output string
+	flags.StringVar(&output, "output", "table", "")
+	flags.StringVar(&output, "o", "table", "")
+
+	var getTable func(ctx context.Context, client *nomad.Client, args []string) (nomad.Table, error)
+	switch args[0] {
+	case "jobs":
+		getTable = func(ctx context.Context, client *nomad.Client, args []string) (nomad.Table, error) {
+			jobs, err := nomad.GetJobs(ctx, client)
+			return nomad.JobsTable(jobs), err
+		}
+
+	case "allocs":
+		var namespace, datacenter string
+		flags.StringVar(&namespace, "namespace", "", "")
+		flags.StringVar(&namespace, "n", "", "")
+		flags.StringVar(&datacenter, "datacenter", "", "")
+		flags.StringVar(&datacenter, "d", "", "")
+		getTable = func(ctx context.Context, client *nomad.Client, args []string) (nomad.Table, error) {
+			if len(args) > 1 {
+				return nomad.Table{}, errUsage
+			}
+			jobID := ""
+			if len(args) == 1 {
+				jobID = args[0]
+			}
+			return nomad.AllocationsTable(ctx, client, jobID, namespace, datacenter)
+		}
+
+	case "logs":
+		var stdErr bool
+		var tailBytes int
+		flags.BoolVar(&stdErr, "stderr", false, "")
+		flags.IntVar(&tailBytes, "tail", 0, "")
+		getTable = func(ctx context.Context, client *nomad.Client, args []string) (nomad.Table, error) {
+			if len(args) != 2 {
+				return nomad.Table{}, errUsage
+			}
+			if tailBytes <= 0 {
+				tailBytes = logTailBytesFromEnv()
+			}
+			logType := nomad.StdOut
+			if stdErr {
+				logType = nomad.StdErr
+			}
+			allocID, err := nomad.ResolveAllocID(ctx, client, args[0])
+			if err != nil {
+				return nomad.Table{}, err
+			}
+			return nomad.LogsTable(ctx, client, allocID, args[1], logType, tailBytes)
+		}
+
+	default:
+		fmt.Fprintf(stderr, "Unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	switch output {
+	case "table", "json", "csv", "tsv":
+	default:
+		fmt.Fprintf(stderr, "Unknown output format %q\n\n%s", output, usage)
+		return errUsage
+	}
+
+	table, err := getTable(context.Background(), newClient(), flags.Args())
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(stderr, usage)
+	}
+	if err != nil {
+		return err
+	}
+	return writeTable(stdout, table, output)
+}
+
+func writeTable(w io.Writer, table nomad.Table, output string) error {
+	switch output {
+	case "json":
+		encoded, err := json.MarshalIndent(table.Objects, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", encoded)
+		return err
+	case "csv", "tsv":
+		delimiter := ','
+		if output == "tsv" {
+			delimiter = '\t'
+		}
+		delimited, err := formatter.TableAsDelimited(table.Columns, table.Rows, delimiter)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, delimited)
+		return err
+	}
+
+	rendered := formatter.GetRenderedTableAsString(table.Columns, table.Rows)
+	for _, row := range append(rendered.HeaderRows, rendered.ContentRows...) {
+		if _, err := fmt.Fprintln(w, strings.TrimRight(row, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"wander/keymap"
+	"wander/nomad"
+	"wander/nomad/nomadtest"
+)
+
+func runTestCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	// pages change the help of global bindings as they're shown, so `wander keys` starts from the defaults
+	keymap.SetOverrides(nil)
+
+	var stdout, stderr bytes.Buffer
+	code := runCommand(args, func() *nomad.Client { return server.Client() }, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"cli_jobs", []string{"jobs"}},
+		{"cli_jobs_json", []string{"jobs", "--output", "json"}},
+		{"cli_allocs", []string{"allocs", "web"}},
+		{"cli_allocs_csv", []string{"allocs", "-o", "csv", "web"}},
+		{"cli_allocs_datacenter", []string{"allocs", "--datacenter", "dc2", "web"}},
+		{"cli_logs", []string{"logs", "0b9c6a5e", "nginx"}},
+		{"cli_logs_stderr_tsv", []string{"logs", "--stderr", "--output", "tsv", "0b9c6a5e", "nginx"}},
+		{"cli_logs_tail_json", []string{"logs", "--tail", "40", "-o", "json", "0b9c6a5e", "nginx"}},
+		{"cli_keys", []string{"keys"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, stdout, stderr := runTestCommand(t, test.args...)
+			if code != 0 {
+				t.Fatalf("exit code %d: %s", code, stderr)
+			}
+			assertGolden(t, test.name, stdout)
+		})
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	tests := []struct {
+		args         []string
+		expectedCode int
+	}{
+		{[]string{"unknown"}, 2},
+		{[]string{"jobs", "--output", "xml"}, 2},
+		{[]string{"logs", "0b9c6a5e"}, 2},
+		{[]string{"logs", "ffffffff", "nginx"}, 1},
+		{[]string{"logs", "0b9c6a5e", "missing"}, 1},
+		{[]string{"allocs", "web", "extra"}, 2},
+	}
+	for _, test := range tests {
+		code, stdout, stderr := runTestCommand(t, test.args...)
+		if code != test.expectedCode {
+			t.Errorf("%v: got exit code %d, expected %d\nstdout: %s\nstderr: %s", test.args, code, test.expectedCode, stdout, stderr)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	nativeclipboard "github.com/atotto/clipboard"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// terminal receives OSC 52 sequences, or is nil if stdout isn't a terminal
+	terminal   = stdoutIfTerminal()
+	nativeCopy = nativeclipboard.WriteAll
+	getenv     = os.Getenv
+)
+
+// Copy puts text on the clipboard. It's sent to the terminal as an OSC 52 escape sequence, which sets the clipboard of
+// the machine the terminal runs on, so works over SSH and in tmux. The native clipboard is set too when running
+// locally, as not every terminal supports OSC 52.
+func Copy(text string) error {
+	osc52Err := errors.New("stdout is not a terminal")
+	if terminal != nil {
+		_, osc52Err = io.WriteString(terminal, osc52(text))
+	}
+
+	// over SSH, the native clipboard is the remote machine's, which isn't the one wanted
+	if osc52Err == nil && getenv("SSH_TTY") != "" {
+		return nil
+	}
+	if err := nativeCopy(text); err != nil && osc52Err != nil {
+		return errors.New("no clipboard available: " + err.Error())
+	}
+	return nil
+}
+
+// osc52 returns the escape sequence that sets the clipboard to text, wrapped so tmux or screen pass it on to the
+// terminal
+func osc52(text string) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(getenv("TERM"), "screen"):
+		return "\x1bP" + sequence + "\x1b\\"
+	}
+	return sequence
+}
+
+func stdoutIfTerminal() io.Writer {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return os.Stdout
+}
@@ -0,0 +1,83 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// fake replaces the terminal, native clipboard and environment until the test ends, returning what was copied natively
+func fake(t *testing.T, out io.Writer, nativeErr error, env map[string]string) *[]string {
+	originalTerminal, originalNativeCopy := terminal, nativeCopy
+	t.Cleanup(func() { terminal, nativeCopy, getenv = originalTerminal, originalNativeCopy, os.Getenv })
+
+	var copied []string
+	terminal = out
+	nativeCopy = func(text string) error {
+		if nativeErr != nil {
+			return nativeErr
+		}
+		copied = append(copied, text)
+		return nil
+	}
+	getenv = func(key string) string { return env[key] }
+	return &copied
+}
+
+func TestOSC52(t *testing.T) {
+	for name, test := range map[string]struct {
+		env      map[string]string
+		expected string
+	}{
+		"terminal": {nil, "\x1b]52;c;aWQ=\a"},
+		"tmux":     {map[string]string{"TMUX": "/tmp/tmux", "TERM": "screen"}, "\x1bPtmux;\x1b\x1b]52;c;aWQ=\a\x1b\\"},
+		"screen":   {map[string]string{"TERM": "screen-256color"}, "\x1bP\x1b]52;c;aWQ=\a\x1b\\"},
+	} {
+		fake(t, nil, nil, test.env)
+		if actual := osc52("id"); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", name, test.expected, actual)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	var out bytes.Buffer
+	copied := fake(t, &out, nil, nil)
+	if err := Copy("id"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\x1b]52;c;aWQ=\a" {
+		t.Errorf("expected OSC 52 sequence, got %q", out.String())
+	}
+	if len(*copied) != 1 || (*copied)[0] != "id" {
+		t.Errorf("expected native copy locally, got %v", *copied)
+	}
+}
+
+func TestCopyOverSSH(t *testing.T) {
+	var out bytes.Buffer
+	copied := fake(t, &out, nil, map[string]string{"SSH_TTY": "/dev/pts/0"})
+	if err := Copy("id"); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() == 0 || len(*copied) != 0 {
+		t.Errorf("expected only OSC 52 over SSH, got %q and native %v", out.String(), *copied)
+	}
+}
+
+func TestCopyWithoutTerminal(t *testing.T) {
+	copied := fake(t, nil, nil, nil)
+	if err := Copy("id"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*copied) != 1 {
+		t.Errorf("expected native copy, got %v", *copied)
+	}
+
+	fake(t, nil, errors.New("no xclip"), nil)
+	if err := Copy("id"); err == nil {
+		t.Error("expected an error with no terminal or native clipboard")
+	}
+}
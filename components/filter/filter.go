@@ -5,6 +5,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"wander/dev"
+	"wander/keymap"
+	"wander/style"
 )
 
 var (
@@ -25,25 +27,12 @@ type Model struct {
 
 func New(prefix string) Model {
 	return Model{
-		prefix: prefix,
-		keyMap: keyMap,
-		PrefixStyle: lipgloss.NewStyle().
-			Padding(0, 3).
-			Border(lipgloss.NormalBorder(), true).
-			Foreground(lipgloss.Color("#FFFFFF")),
-		FilterStyle: lipgloss.NewStyle().
-			Margin(0, 1).
-			Foreground(lipgloss.Color("#8E8E8E")),
-		AppliedFilterStyle: lipgloss.NewStyle().
-			Margin(0, 1).
-			Padding(0, 1).
-			Foreground(lipgloss.Color("#000000")).
-			Background(lipgloss.Color("#00A095")),
-		EditingFilterStyle: lipgloss.NewStyle().
-			Margin(0, 1).
-			Padding(0, 1).
-			Foreground(lipgloss.Color("#000000")).
-			Background(lipgloss.Color("6")),
+		prefix:             prefix,
+		keyMap:             keyMap,
+		PrefixStyle:        style.FilterPrefix,
+		FilterStyle:        style.Filter,
+		AppliedFilterStyle: style.AppliedFilter,
+		EditingFilterStyle: style.EditingFilter,
 	}
 }
 
@@ -81,7 +70,7 @@ func (m Model) View() string {
 	case m.focus:
 		filterString = "type to filter"
 	default:
-		filterString = "<'/' to filter>"
+		filterString = fmt.Sprintf("<'%s' to filter>", keymap.KeyMap.Filter.Help().Key)
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Center, m.PrefixStyle.Render(m.prefix), m.formatFilterString(filterString))
 }
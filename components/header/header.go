@@ -12,6 +12,7 @@ type Model struct {
 	logo     string
 	nomadUrl string
 	KeyHelp  string
+	Region   string
 }
 
 func New(logo string, nomadUrl, keyHelp string) (m Model) {
@@ -27,11 +28,23 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	styledKeyHelp := style.KeyHelp.Render(m.KeyHelp)
+	return lipgloss.JoinHorizontal(lipgloss.Center, m.left(), styledKeyHelp)
+}
+
+// left renders the logo and cluster details shown left of the key help
+func (m Model) left() string {
 	logo := style.Logo.Render(m.logo)
 	clusterUrl := style.ClusterUrl.Render(fmt.Sprintf("URL: %s", m.nomadUrl))
-	left := style.Header.Render(lipgloss.JoinVertical(lipgloss.Center, logo, clusterUrl))
-	styledKeyHelp := style.KeyHelp.Render(m.KeyHelp)
-	return lipgloss.JoinHorizontal(lipgloss.Center, left, styledKeyHelp)
+	if m.Region != "" {
+		clusterUrl = lipgloss.JoinVertical(lipgloss.Center, clusterUrl, style.ClusterUrl.Render(fmt.Sprintf("Region: %s", m.Region)))
+	}
+	return style.Header.Render(lipgloss.JoinVertical(lipgloss.Center, logo, clusterUrl))
+}
+
+// KeyHelpWidth is how many columns each line of key help can take up in a header width columns wide
+func (m Model) KeyHelpWidth(width int) int {
+	return width - lipgloss.Width(m.left()) - style.KeyHelp.GetHorizontalFrameSize()
 }
 
 func (m Model) ViewHeight() int {
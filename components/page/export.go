@@ -0,0 +1,221 @@
+package page
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"wander/components/viewport"
+	"wander/fileio"
+	"wander/formatter"
+)
+
+// exportFormat is a format a page can be saved in, chosen by the extension of the file name
+type exportFormat int8
+
+const (
+	textFormat exportFormat = iota
+	csvFormat
+	tsvFormat
+	jsonFormat
+	markdownFormat
+)
+
+func exportFormatFromFileName(fileName string) exportFormat {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return csvFormat
+	case ".tsv":
+		return tsvFormat
+	case ".json":
+		return jsonFormat
+	case ".md", ".markdown":
+		return markdownFormat
+	}
+	return textFormat
+}
+
+func (f exportFormat) String() string {
+	switch f {
+	case csvFormat:
+		return "csv"
+	case tsvFormat:
+		return "tsv"
+	case jsonFormat:
+		return "json"
+	case markdownFormat:
+		return "markdown"
+	}
+	return "text"
+}
+
+func saveCommand(fileName string, header []string, rows []Row) tea.Cmd {
+	return func() tea.Msg {
+		content, err := export(exportFormatFromFileName(fileName), header, rows)
+		if err != nil {
+			return viewport.SaveStatusMsg{SuccessMessage: "", Err: err.Error()}
+		}
+		savePathWithFileName, err := fileio.SaveToFile(fileName, content)
+		if err != nil {
+			return viewport.SaveStatusMsg{SuccessMessage: "", Err: err.Error()}
+		}
+		successMessage := fmt.Sprintf("Success: saved to %s", savePathWithFileName)
+		return viewport.SaveStatusMsg{SuccessMessage: successMessage, Err: ""}
+	}
+}
+
+// export renders a page's header and rows in format. Table formats only include rows that are part of a table.
+func export(format exportFormat, header []string, rows []Row) (string, error) {
+	switch format {
+	case csvFormat, tsvFormat:
+		return exportDelimited(format, rows)
+	case jsonFormat:
+		return exportJSON(header, rows)
+	case markdownFormat:
+		return exportMarkdown(header, rows), nil
+	}
+	return exportText(header, rows), nil
+}
+
+func exportText(header []string, rows []Row) string {
+	var b strings.Builder
+	for _, line := range header {
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	for _, row := range rows {
+		b.WriteString(strings.TrimRight(row.Row, " ") + "\n")
+	}
+	return b.String()
+}
+
+// tableGroup is a run of consecutive rows from the same table
+type tableGroup struct {
+	columns []string
+	cells   [][]string
+}
+
+func (g tableGroup) sameTable(data *RowData) bool {
+	return len(g.cells) > 0 && strings.Join(g.columns, "\x00") == strings.Join(data.Columns, "\x00")
+}
+
+// exportDelimited renders each table on the page as csv or tsv, separated by blank lines
+func exportDelimited(format exportFormat, rows []Row) (string, error) {
+	var groups []tableGroup
+	for _, row := range rows {
+		if row.Data == nil {
+			continue
+		}
+		if len(groups) == 0 || !groups[len(groups)-1].sameTable(row.Data) {
+			groups = append(groups, tableGroup{columns: row.Data.Columns})
+		}
+		groups[len(groups)-1].cells = append(groups[len(groups)-1].cells, row.Data.Cells)
+	}
+	if len(groups) == 0 {
+		return "", fmt.Errorf("no table to save as %s, save as .txt instead", format)
+	}
+
+	delimiter := ','
+	if format == tsvFormat {
+		delimiter = '\t'
+	}
+	var tables []string
+	for _, group := range groups {
+		table, err := formatter.TableAsDelimited(group.columns, group.cells, delimiter)
+		if err != nil {
+			return "", err
+		}
+		tables = append(tables, table)
+	}
+	return strings.Join(tables, "\n"), nil
+}
+
+// exportJSON renders the API objects the rows were made from. Pages without them are rendered as objects of each
+// table row's cells by column, or as text if they have no table, e.g. specs that are json already.
+func exportJSON(header []string, rows []Row) (string, error) {
+	var objects []interface{}
+	seen := make(map[interface{}]bool)
+	for _, row := range rows {
+		if row.Data == nil || row.Data.Object == nil {
+			continue
+		}
+		object := row.Data.Object
+		if reflect.ValueOf(object).Kind() == reflect.Ptr {
+			if seen[object] {
+				continue
+			}
+			seen[object] = true
+		}
+		objects = append(objects, object)
+	}
+
+	if len(objects) == 0 {
+		for _, row := range rows {
+			if row.Data == nil {
+				continue
+			}
+			cellsByColumn := make(map[string]string)
+			for i, column := range row.Data.Columns {
+				if i < len(row.Data.Cells) {
+					cellsByColumn[column] = row.Data.Cells[i]
+				}
+			}
+			objects = append(objects, cellsByColumn)
+		}
+	}
+	if len(objects) == 0 {
+		return exportText(header, rows), nil
+	}
+
+	encoded, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return "", errors.New("could not encode page as json: " + err.Error())
+	}
+	return string(encoded) + "\n", nil
+}
+
+// exportMarkdown renders tables on the page as markdown tables and other rows as lines of text. Pages without tables
+// are rendered as a code block.
+func exportMarkdown(header []string, rows []Row) string {
+	hasTable := false
+	for _, row := range rows {
+		hasTable = hasTable || row.Data != nil
+	}
+	if !hasTable {
+		return "```\n" + exportText(header, rows) + "```\n"
+	}
+
+	// tables are separated from surrounding text by blank lines, or they would be read as part of a paragraph
+	var b strings.Builder
+	var group tableGroup
+	flush := func() {
+		if len(group.cells) == 0 {
+			return
+		}
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString(formatter.TableAsMarkdown(group.columns, group.cells))
+		b.WriteString("\n")
+		group = tableGroup{}
+	}
+	for _, row := range rows {
+		if row.Data == nil {
+			flush()
+			line := strings.TrimRight(row.Row, " ")
+			if line != "" || (b.Len() > 0 && !strings.HasSuffix(b.String(), "\n\n")) {
+				b.WriteString(line + "\n")
+			}
+			continue
+		}
+		if !group.sameTable(row.Data) {
+			flush()
+			group.columns = row.Data.Columns
+		}
+		group.cells = append(group.cells, row.Data.Cells)
+	}
+	flush()
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
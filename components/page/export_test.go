@@ -0,0 +1,143 @@
+package page
+
+import (
+	"strings"
+	"testing"
+)
+
+var exportColumns = []string{"Name", "Size"}
+
+// sectionRows is a page with text, then a table, then a second table with different columns
+func sectionRows() []Row {
+	return []Row{
+		{Row: "Details"},
+		{Row: "ID  abc   "},
+		{Row: ""},
+		{Row: "Files"},
+		{Row: "a.txt  1 B", Data: &RowData{Columns: exportColumns, Cells: []string{"a.txt", "1 B"}}},
+		{Row: "b|c    2 B", Data: &RowData{Columns: exportColumns, Cells: []string{"b|c", "2 B"}}},
+		{Row: ""},
+		{Row: "Owners"},
+		{Row: "leo", Data: &RowData{Columns: []string{"Owner"}, Cells: []string{"leo"}}},
+	}
+}
+
+func TestExportFormatFromFileName(t *testing.T) {
+	for fileName, expected := range map[string]exportFormat{
+		"":              textFormat,
+		"out":           textFormat,
+		"out.txt":       textFormat,
+		"dir/out.CSV":   csvFormat,
+		"out.tsv":       tsvFormat,
+		"~/out.json":    jsonFormat,
+		"out.md":        markdownFormat,
+		"out.markdown":  markdownFormat,
+		"out.csv.bak":   textFormat,
+		"csv.dir/out.x": textFormat,
+	} {
+		if actual := exportFormatFromFileName(fileName); actual != expected {
+			t.Errorf("%q: expected %s, got %s", fileName, expected, actual)
+		}
+	}
+}
+
+func TestExportText(t *testing.T) {
+	actual, err := export(textFormat, []string{"Header  "}, sectionRows()[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Header\nDetails\nID  abc\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestExportDelimited(t *testing.T) {
+	actual, err := export(csvFormat, nil, sectionRows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Name,Size\na.txt,1 B\nb|c,2 B\n\nOwner\nleo\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	actual, err = export(tsvFormat, nil, sectionRows()[4:6])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Name\tSize\na.txt\t1 B\nb|c\t2 B\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if _, err = export(csvFormat, nil, sectionRows()[:2]); err == nil {
+		t.Error("expected an error for a page without a table")
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	type file struct{ Name string }
+	shared := &file{Name: "a.txt"}
+	rows := []Row{
+		{Row: "a.txt stdout", Data: &RowData{Columns: exportColumns, Object: shared}},
+		{Row: "a.txt stderr", Data: &RowData{Columns: exportColumns, Object: shared}},
+		{Row: "b.txt", Data: &RowData{Columns: exportColumns, Object: file{Name: "b.txt"}}},
+	}
+	actual, err := export(jsonFormat, nil, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(actual, `"Name"`) != 2 {
+		t.Errorf("expected objects shared by rows once, got %s", actual)
+	}
+
+	actual, err = export(jsonFormat, nil, sectionRows()[4:6])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(actual, `"Name": "b|c"`) || !strings.Contains(actual, `"Size": "2 B"`) {
+		t.Errorf("expected cells by column, got %s", actual)
+	}
+
+	actual, err = export(jsonFormat, nil, []Row{{Row: `{"ID": "abc"}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{\"ID\": \"abc\"}\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	actual, err := export(markdownFormat, nil, sectionRows())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"Details",
+		"ID  abc",
+		"",
+		"Files",
+		"",
+		"| Name | Size |",
+		"| --- | --- |",
+		"| a.txt | 1 B |",
+		`| b\|c | 2 B |`,
+		"",
+		"Owners",
+		"",
+		"| Owner |",
+		"| --- |",
+		"| leo |",
+		"",
+	}, "\n")
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	actual, err = export(markdownFormat, []string{"Header"}, sectionRows()[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "```\nHeader\nDetails\nID  abc\n```\n"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"strings"
+	"unicode"
 	"wander/components/filter"
 	"wander/components/viewport"
 	"wander/dev"
@@ -15,13 +16,33 @@ import (
 type Model struct {
 	width, height int
 	pageData      data
+	header        []string
 	viewport      viewport.Model
 	filter        filter.Model
 	loadingString string
 	loading       bool
 	ViewportStyle lipgloss.Style
+
+	// rowStyles maps a row Key to the style its row is rendered with, overriding the viewport's content style
+	rowStyles map[string]lipgloss.Style
+
+	// marked holds the ids of marked rows, so marks are kept for rows hidden by the filter and across reloads
+	marked map[string]bool
+
+	// bookmarks maps a letter to the index in pageData.All of the row it was set on. pendingBookmark is set while
+	// waiting for the letter of the bookmark to set or go to.
+	bookmarks       map[rune]int
+	pendingBookmark bookmarkAction
 }
 
+type bookmarkAction int
+
+const (
+	noBookmarkAction bookmarkAction = iota
+	setBookmarkAction
+	goToBookmarkAction
+)
+
 func New(
 	width, height int,
 	filterPrefix, loadingString string,
@@ -38,6 +59,8 @@ func New(
 		filter:        pageFilter,
 		loadingString: loadingString,
 		loading:       true,
+		marked:        make(map[string]bool),
+		bookmarks:     make(map[rune]int),
 	}
 	return model
 }
@@ -49,15 +72,32 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
-	if m.viewport.Saving() {
+	if m.viewport.Saving() || m.viewport.Jumping() {
 		m.viewport, cmd = m.viewport.Update(msg)
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 	}
 
 	switch msg := msg.(type) {
+	case viewport.SaveRequestMsg:
+		rows := m.pageData.Filtered
+		if msg.AllRows {
+			rows = m.pageData.All
+		} else if marked := m.MarkedPageRows(); len(marked) > 0 {
+			rows = marked
+		}
+		return m, saveCommand(msg.FileName, m.header, rows)
+
 	case tea.KeyMsg:
+		if m.awaitingBookmark() {
+			return m, m.finishBookmark(msg)
+		}
+
 		if key.Matches(msg, keymap.KeyMap.Back) {
+			if len(m.marked) > 0 {
+				m.ClearMarks()
+				return m, nil
+			}
 			m.clearFilter()
 		}
 
@@ -71,10 +111,18 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			case key.Matches(msg, keymap.KeyMap.Filter):
 				m.filter.Focus()
 				return m, nil
+
+			case key.Matches(msg, keymap.KeyMap.SetBookmark, keymap.KeyMap.GoToBookmark):
+				m.pendingBookmark = setBookmarkAction
+				if key.Matches(msg, keymap.KeyMap.GoToBookmark) {
+					m.pendingBookmark = goToBookmarkAction
+				}
+				return m, nil
 			}
 
 			m.viewport, cmd = m.viewport.Update(msg)
 			cmds = append(cmds, cmd)
+			m.updateMarks()
 		}
 
 		prevFilter := m.filter.Filter
@@ -101,7 +149,13 @@ func (m *Model) SetWindowSize(width, height int) {
 	m.viewport.SetSize(width, height-m.filter.ViewHeight())
 }
 
+// FilteredText returns the page's header and the rows matching its filter as text
+func (m Model) FilteredText() string {
+	return exportText(m.header, m.pageData.Filtered)
+}
+
 func (m *Model) SetHeader(header []string) {
+	m.header = header
 	m.viewport.SetHeader(header)
 }
 
@@ -110,12 +164,37 @@ func (m *Model) SetViewportStyle(headerStyle, contentStyle lipgloss.Style) {
 	m.viewport.ContentStyle = contentStyle
 }
 
+func (m *Model) SetRowStyles(rowStyles map[string]lipgloss.Style) {
+	m.rowStyles = rowStyles
+	m.updateViewportStyles()
+}
+
 func (m *Model) SetLoading(isLoading bool) {
 	m.loading = isLoading
 }
 
+// SetAllPageData sets the page data, clearing bookmarks as the rows they were set on may have changed
 func (m *Model) SetAllPageData(allPageData []Row) {
 	m.pageData.All = allPageData
+	m.bookmarks = make(map[rune]int)
+	m.updateViewport()
+}
+
+// PrependPageData sets the page data where the first numPrepended rows are newly added before the existing rows,
+// keeping the cursor and bookmarks on the rows they were on
+func (m *Model) PrependPageData(allPageData []Row, numPrepended int) {
+	cursorRow := m.viewport.CursorRow()
+	bookmarks := m.bookmarks
+	m.SetAllPageData(allPageData)
+	for letter, idx := range bookmarks {
+		m.bookmarks[letter] = idx + numPrepended
+	}
+	numPrependedFiltered := len(m.filterRows(allPageData[:min(numPrepended, len(allPageData))]))
+	m.viewport.SetCursorRow(cursorRow + numPrependedFiltered)
+}
+
+func (m *Model) SetFilter(filter string) {
+	m.filter.SetFilter(filter)
 	m.updateViewport()
 }
 
@@ -123,6 +202,16 @@ func (m *Model) SetFilterPrefix(prefix string) {
 	m.filter.SetPrefix(prefix)
 }
 
+// SetViewportCursorToKey moves the cursor to the first visible row with key, if any
+func (m *Model) SetViewportCursorToKey(key string) {
+	for idx, row := range m.pageData.Filtered {
+		if row.Key == key {
+			m.viewport.SetCursorRow(idx)
+			return
+		}
+	}
+}
+
 func (m *Model) SetViewportCursorToBottom() {
 	m.viewport.SetCursorRow(len(m.pageData.Filtered) - 1)
 }
@@ -135,6 +224,10 @@ func (m Model) Loading() bool {
 	return m.loading
 }
 
+func (m Model) ViewportCursorAtTop() bool {
+	return m.viewport.CursorRow() == 0
+}
+
 func (m Model) GetSelectedPageRow() (Row, error) {
 	cursorRow := m.viewport.CursorRow()
 	if filtered := m.pageData.Filtered; len(filtered) > 0 && cursorRow < len(filtered) {
@@ -143,6 +236,42 @@ func (m Model) GetSelectedPageRow() (Row, error) {
 	return Row{}, fmt.Errorf("bad thing")
 }
 
+// MarkedPageRows returns the marked rows in page order, including any hidden by the filter
+func (m Model) MarkedPageRows() []Row {
+	var rows []Row
+	for _, row := range m.pageData.All {
+		if m.marked[row.id()] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// HasMarks reports whether any rows are marked
+func (m Model) HasMarks() bool {
+	return len(m.marked) > 0
+}
+
+func (m *Model) ClearMarks() {
+	m.marked = make(map[string]bool)
+	m.viewport.ClearMarks()
+}
+
+// updateMarks records which of the rows matching the filter the viewport has marked
+func (m *Model) updateMarks() {
+	markedRows := make(map[int]bool)
+	for _, idx := range m.viewport.MarkedRows() {
+		markedRows[idx] = true
+	}
+	for idx, row := range m.pageData.Filtered {
+		if markedRows[idx] {
+			m.marked[row.id()] = true
+		} else {
+			delete(m.marked, row.id())
+		}
+	}
+}
+
 func (m Model) FilterFocused() bool {
 	return m.filter.Focused()
 }
@@ -155,6 +284,70 @@ func (m Model) ViewportSaving() bool {
 	return m.viewport.Saving()
 }
 
+// TypingInput reports whether keys are being typed into the filter or a dialog, or name a bookmark, rather than being
+// commands
+func (m Model) TypingInput() bool {
+	return m.filter.Focused() || m.viewport.Saving() || m.viewport.Jumping() || m.awaitingBookmark()
+}
+
+func (m Model) awaitingBookmark() bool {
+	return m.pendingBookmark != noBookmarkAction
+}
+
+// finishBookmark sets or goes to the bookmark named by the letter typed after the bookmark key. Any other key cancels.
+func (m *Model) finishBookmark(msg tea.KeyMsg) tea.Cmd {
+	action := m.pendingBookmark
+	m.pendingBookmark = noBookmarkAction
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || !unicode.IsLetter(msg.Runes[0]) {
+		return nil
+	}
+	letter := msg.Runes[0]
+
+	if action == setBookmarkAction {
+		if idx, ok := m.allIndex(m.viewport.CurrentLine()); ok {
+			m.bookmarks[letter] = idx
+		}
+		return nil
+	}
+
+	allIdx, exists := m.bookmarks[letter]
+	if !exists {
+		return jumpError(fmt.Sprintf("No bookmark '%c", letter))
+	}
+	filteredIdx, ok := m.filteredIndex(allIdx)
+	if !ok {
+		return jumpError(fmt.Sprintf("Bookmark '%c is hidden by the filter", letter))
+	}
+	m.viewport.GoToLine(filteredIdx)
+	return nil
+}
+
+func jumpError(err string) tea.Cmd {
+	return func() tea.Msg { return viewport.JumpErrorMsg{Err: err} }
+}
+
+// allIndex returns the index in pageData.All of the row at filteredIdx in pageData.Filtered
+func (m Model) allIndex(filteredIdx int) (int, bool) {
+	for allIdx, row := range m.pageData.All {
+		if m.matchesFilter(row) {
+			if filteredIdx == 0 {
+				return allIdx, true
+			}
+			filteredIdx--
+		}
+	}
+	return 0, false
+}
+
+// filteredIndex returns the index in pageData.Filtered of the row at allIdx in pageData.All, or false if the filter
+// hides it
+func (m Model) filteredIndex(allIdx int) (int, bool) {
+	if allIdx >= len(m.pageData.All) || !m.matchesFilter(m.pageData.All[allIdx]) {
+		return 0, false
+	}
+	return len(m.filterRows(m.pageData.All[:allIdx])), true
+}
+
 func (m *Model) clearFilter() {
 	m.filter.BlurAndClear()
 	m.updateViewport()
@@ -164,19 +357,57 @@ func (m *Model) updateViewport() {
 	m.viewport.Highlight = m.filter.Filter
 	m.updateFilteredData()
 	m.viewport.SetContent(rowsToStrings(m.pageData.Filtered))
+	m.updateViewportStyles()
 	m.viewport.SetCursorRow(0)
+
+	// rows that no longer exist can't be acted on, so their marks are dropped
+	exists := make(map[string]bool)
+	for _, row := range m.pageData.All {
+		exists[row.id()] = true
+	}
+	var markedRows []int
+	for id := range m.marked {
+		if !exists[id] {
+			delete(m.marked, id)
+		}
+	}
+	for idx, row := range m.pageData.Filtered {
+		if m.marked[row.id()] {
+			markedRows = append(markedRows, idx)
+		}
+	}
+	m.viewport.SetMarkedRows(markedRows)
+}
+
+func (m *Model) updateViewportStyles() {
+	contentStyles := make(map[int]lipgloss.Style)
+	if len(m.rowStyles) > 0 {
+		for idx, row := range m.pageData.Filtered {
+			if rowStyle, exists := m.rowStyles[row.Key]; exists {
+				contentStyles[idx] = rowStyle
+			}
+		}
+	}
+	m.viewport.SetContentStyles(contentStyles)
 }
 
 func (m *Model) updateFilteredData() {
+	m.pageData.Filtered = m.filterRows(m.pageData.All)
+}
+
+func (m Model) filterRows(rows []Row) []Row {
 	if m.filter.Filter == "" {
-		m.pageData.Filtered = m.pageData.All
-	} else {
-		var filteredData []Row
-		for _, entry := range m.pageData.All {
-			if strings.Contains(entry.Row, m.filter.Filter) {
-				filteredData = append(filteredData, entry)
-			}
+		return rows
+	}
+	var filteredRows []Row
+	for _, entry := range rows {
+		if m.matchesFilter(entry) {
+			filteredRows = append(filteredRows, entry)
 		}
-		m.pageData.Filtered = filteredData
 	}
+	return filteredRows
+}
+
+func (m Model) matchesFilter(row Row) bool {
+	return strings.Contains(row.Row, m.filter.Filter)
 }
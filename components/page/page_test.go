@@ -0,0 +1,107 @@
+package page
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"reflect"
+	"testing"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func newTestPage(rows ...string) Model {
+	m := New(80, 20, "Test", "loading", true, false)
+	var data []Row
+	for _, row := range rows {
+		data = append(data, Row{Key: row, Row: row})
+	}
+	m.SetAllPageData(data)
+	return m
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		m, _ = m.Update(runes(k))
+	}
+	return m
+}
+
+func assertMarkedRows(t *testing.T, m Model, expected ...string) {
+	t.Helper()
+	var actual []string
+	for _, row := range m.MarkedPageRows() {
+		actual = append(actual, row.Key)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v marked, got %v", expected, actual)
+	}
+}
+
+func TestMarksKeptWhenFiltered(t *testing.T) {
+	m := press(newTestPage("api", "web", "worker"), "x")
+	m.SetFilter("w")
+	m = press(m, "j", "x")
+	assertMarkedRows(t, m, "api", "worker")
+
+	m.SetFilter("")
+	if actual := m.viewport.MarkedRows(); !reflect.DeepEqual(actual, []int{0, 2}) {
+		t.Errorf("expected marks shown on rows 0 and 2 without the filter, got %v", actual)
+	}
+}
+
+func TestMarksKeptOnReload(t *testing.T) {
+	m := press(newTestPage("api", "web", "worker"), "j", "v", "j")
+	assertMarkedRows(t, m, "web", "worker")
+
+	m.SetAllPageData([]Row{{Key: "web", Row: "web"}, {Key: "api", Row: "api"}})
+	assertMarkedRows(t, m, "web")
+}
+
+func TestBackClearsMarks(t *testing.T) {
+	m := press(newTestPage("api", "web"), "x")
+	m.SetFilter("a")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.HasMarks() || !m.FilterApplied() {
+		t.Error("expected back to clear marks before the filter")
+	}
+}
+
+func assertSelected(t *testing.T, m Model, expected string) {
+	t.Helper()
+	if row, err := m.GetSelectedPageRow(); err != nil || row.Row != expected {
+		t.Errorf("expected %s selected, got %q", expected, row.Row)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	m := press(newTestPage("api", "web", "worker"), "j", "m", "a", "j", "'", "a")
+	assertSelected(t, m, "web")
+	if m.TypingInput() {
+		t.Error("expected bookmark letter to be consumed")
+	}
+
+	// bookmarks are kept by row, so still go to it with a filter applied
+	m.SetFilter("w")
+	m = press(m, "'", "a")
+	assertSelected(t, m, "web")
+
+	m.SetFilter("worker")
+	m, cmd := press(m, "'").Update(runes("a"))
+	if cmd == nil {
+		t.Error("expected an error for a bookmark hidden by the filter")
+	}
+	assertSelected(t, m, "worker")
+}
+
+func TestBookmarksShiftedOnPrepend(t *testing.T) {
+	m := press(newTestPage("web", "worker"), "j", "m", "w", "g")
+	m.PrependPageData([]Row{{Row: "api"}, {Row: "web"}, {Row: "worker"}}, 1)
+	m = press(m, "'", "w")
+	assertSelected(t, m, "worker")
+
+	m.SetAllPageData([]Row{{Row: "api"}})
+	if _, cmd := press(m, "'").Update(runes("w")); cmd == nil {
+		t.Error("expected bookmarks cleared with new page data")
+	}
+}
@@ -2,12 +2,27 @@ package page
 
 type Row struct {
 	Key, Row string
+	// Data is the row before rendering if it's part of a table, so the page can be saved as structured data
+	Data *RowData
+}
+
+// RowData is a table row's cells and the API object they were taken from
+type RowData struct {
+	Columns, Cells []string
+	// Object is the API object the row was made from, if any. Rows made from the same object should share a pointer
+	// to it, so it's only saved once.
+	Object interface{}
 }
 
 func (r Row) String() string {
 	return r.Row
 }
 
+// id identifies a row across reloads and filter changes, as keys alone aren't unique on every page, e.g. logs
+func (r Row) id() string {
+	return r.Key + "\x00" + r.Row
+}
+
 func rowsToStrings(rows []Row) []string {
 	var strs []string
 	for _, row := range rows {
@@ -19,3 +34,10 @@ func rowsToStrings(rows []Row) []string {
 type data struct {
 	All, Filtered []Row
 }
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
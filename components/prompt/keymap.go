@@ -0,0 +1,37 @@
+package prompt
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"wander/keymap"
+)
+
+type promptKeyMap struct {
+	Submit key.Binding
+	Cancel key.Binding
+	Yes    key.Binding
+	No     key.Binding
+}
+
+// GetKeyMap returns the prompt's keymap, with any keys overridden by keymap.SetOverrides
+func GetKeyMap() promptKeyMap {
+	keyMap := promptKeyMap{
+		Submit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "submit"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		Yes: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "yes"),
+		),
+		No: key.NewBinding(
+			key.WithKeys("n", "N"),
+			key.WithHelp("n", "no"),
+		),
+	}
+	keymap.Override(keymap.Prompt, &keyMap)
+	return keyMap
+}
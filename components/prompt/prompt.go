@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"wander/dev"
+	"wander/style"
+)
+
+// SubmitMsg is sent when the prompt is submitted. For confirmations, Value is "y".
+type SubmitMsg struct{ Value string }
+
+// CancelMsg is sent when the prompt is cancelled
+type CancelMsg struct{}
+
+// Model is a single line prompt that either asks for a yes/no confirmation or for text input
+type Model struct {
+	question string
+	confirm  bool
+	active   bool
+	width    int
+	keyMap   promptKeyMap
+	input    textinput.Model
+	Style    lipgloss.Style
+}
+
+func New(width int) Model {
+	input := textinput.New()
+	input.PromptStyle = style.Dialog
+	input.PlaceholderStyle = style.Dialog
+	input.TextStyle = style.Dialog
+	return Model{
+		width:  width,
+		keyMap: GetKeyMap(),
+		input:  input,
+		Style:  style.Dialog,
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	dev.Debug(fmt.Sprintf("prompt %T", msg))
+	if !m.active {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keyMap.Cancel):
+			m.deactivate()
+			return m, func() tea.Msg { return CancelMsg{} }
+
+		case m.confirm && key.Matches(msg, m.keyMap.No):
+			m.deactivate()
+			return m, func() tea.Msg { return CancelMsg{} }
+
+		case m.confirm && key.Matches(msg, m.keyMap.Yes):
+			m.deactivate()
+			return m, func() tea.Msg { return SubmitMsg{Value: "y"} }
+
+		case !m.confirm && key.Matches(msg, m.keyMap.Submit):
+			value := m.input.Value()
+			m.deactivate()
+			return m, func() tea.Msg { return SubmitMsg{Value: value} }
+		}
+	}
+
+	if !m.confirm {
+		m.input, cmd = m.input.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if !m.active {
+		return ""
+	}
+	if m.confirm {
+		return m.Style.Copy().Width(m.width).MaxWidth(m.width).Render(fmt.Sprintf("%s (y/n)", m.question))
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(m.Style.Render(m.question+" ") + m.input.View())
+}
+
+// Confirm activates the prompt as a yes/no question
+func (m *Model) Confirm(question string) {
+	m.question = question
+	m.confirm = true
+	m.active = true
+}
+
+// Ask activates the prompt as a question with text input, prefilled with initialValue
+func (m *Model) Ask(question, placeholder, initialValue string) tea.Cmd {
+	m.question = question
+	m.confirm = false
+	m.active = true
+	m.input.Reset()
+	m.input.Prompt = "> "
+	m.input.Placeholder = placeholder
+	m.input.SetValue(initialValue)
+	m.input.CursorEnd()
+	m.input.Focus()
+	return textinput.Blink
+}
+
+func (m Model) Active() bool {
+	return m.active
+}
+
+func (m *Model) SetWidth(width int) {
+	m.width = width
+}
+
+func (m *Model) deactivate() {
+	m.active = false
+	m.input.Blur()
+	m.input.Reset()
+}
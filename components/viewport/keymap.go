@@ -1,27 +1,37 @@
 package viewport
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"wander/keymap"
+)
 
 const spacebar = " "
 
 type viewportKeyMap struct {
-	PageDown     key.Binding
-	PageUp       key.Binding
-	HalfPageUp   key.Binding
-	HalfPageDown key.Binding
-	Up           key.Binding
-	Down         key.Binding
-	Left         key.Binding
-	Right        key.Binding
-	Top          key.Binding
-	Bottom       key.Binding
-	Save         key.Binding
-	CancelSave   key.Binding
-	ConfirmSave  key.Binding
+	PageDown       key.Binding
+	PageUp         key.Binding
+	HalfPageUp     key.Binding
+	HalfPageDown   key.Binding
+	Up             key.Binding
+	Down           key.Binding
+	Left           key.Binding
+	Right          key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	Save           key.Binding
+	CancelSave     key.Binding
+	ConfirmSave    key.Binding
+	ToggleSaveRows key.Binding
+	Mark           key.Binding
+	MarkRange      key.Binding
+	MarkUp         key.Binding
+	MarkDown       key.Binding
+	Jump           key.Binding
 }
 
+// GetKeyMap returns the viewport's keymap, with any keys overridden by keymap.SetOverrides
 func GetKeyMap() viewportKeyMap {
-	return viewportKeyMap{
+	keyMap := viewportKeyMap{
 		PageDown: key.NewBinding(
 			key.WithKeys("pgdown", spacebar, "f"),
 			key.WithHelp("f/pgdn", "pgdn"),
@@ -74,5 +84,31 @@ func GetKeyMap() viewportKeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "confirm"),
 		),
+		ToggleSaveRows: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "save all rows/filtered rows"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "mark"),
+		),
+		MarkRange: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "mark range"),
+		),
+		MarkUp: key.NewBinding(
+			key.WithKeys("K"),
+			key.WithHelp("K", "mark up"),
+		),
+		MarkDown: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "mark down"),
+		),
+		Jump: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "go to line/time"),
+		),
 	}
+	keymap.Override(keymap.Viewport, &keyMap)
+	return keyMap
 }
@@ -8,11 +8,14 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 	"wander/constants"
 	"wander/dev"
-	"wander/fileio"
+	"wander/formatter"
 	"wander/style"
 )
 
@@ -23,6 +26,25 @@ type SaveStatusMsg struct {
 	SuccessMessage, Err string
 }
 
+// SaveRequestMsg is sent when the save dialog is confirmed, for the page to save its rows in the format of the file
+type SaveRequestMsg struct {
+	FileName string
+	// AllRows is true if rows hidden by the page's filter should be saved too
+	AllRows bool
+}
+
+// JumpErrorMsg is sent when the line or time typed in the jump dialog can't be gone to
+type JumpErrorMsg struct {
+	Err string
+}
+
+const (
+	jumpPrompt           = ": "
+	savePrompt           = "> "
+	saveAllRowsPrompt    = "all rows > "
+	saveMarkedRowsPrompt = "marked rows > "
+)
+
 type Model struct {
 	// cursorRow is the row index of the cursor.
 	cursorRow int
@@ -33,6 +55,7 @@ type Model struct {
 	// Styles
 	HeaderStyle    lipgloss.Style
 	CursorRowStyle lipgloss.Style
+	MarkedRowStyle lipgloss.Style
 	HighlightStyle lipgloss.Style
 	ContentStyle   lipgloss.Style
 	FooterStyle    lipgloss.Style
@@ -44,6 +67,8 @@ type Model struct {
 	cursorEnabled bool
 	wrapText      bool
 	saveDialog    textinput.Model
+	saveAllRows   bool
+	jumpDialog    textinput.Model
 
 	// Currently, causes flickering if enabled.
 	mouseWheelEnabled bool
@@ -57,27 +82,45 @@ type Model struct {
 	header        []string
 	content       []string
 	maxLineLength int
+
+	// contentStyles overrides ContentStyle for specific content line indices
+	contentStyles map[int]lipgloss.Style
+
+	// marked are the content line indices marked for saving, copying or bulk actions. markAnchor is where the range
+	// being marked starts, which extends to the cursor as it moves, or -1 if no range is being marked.
+	marked     map[int]bool
+	markAnchor int
 }
 
 func New(width, height int) (m Model) {
 	m.width, m.height = width, height
 
 	m.saveDialog = textinput.New()
-	m.saveDialog.Prompt = "> "
+	m.saveDialog.Prompt = savePrompt
 	m.saveDialog.Placeholder = m.getSaveDialogPlaceholder()
-	m.saveDialog.PromptStyle = lipgloss.NewStyle().Background(lipgloss.Color("#FF0000")).Foreground(lipgloss.Color("#000000"))
-	m.saveDialog.PlaceholderStyle = lipgloss.NewStyle().Background(lipgloss.Color("#FF0000")).Foreground(lipgloss.Color("#000000"))
-	m.saveDialog.TextStyle = lipgloss.NewStyle().Background(lipgloss.Color("#FF0000")).Foreground(lipgloss.Color("#000000"))
+	m.saveDialog.PromptStyle = style.Dialog
+	m.saveDialog.PlaceholderStyle = style.Dialog
+	m.saveDialog.TextStyle = style.Dialog
+
+	m.jumpDialog = textinput.New()
+	m.jumpDialog.Prompt = jumpPrompt
+	m.jumpDialog.Placeholder = m.getDialogPlaceholder(constants.JumpDialogPlaceholder, jumpPrompt)
+	m.jumpDialog.PromptStyle = m.saveDialog.PromptStyle
+	m.jumpDialog.PlaceholderStyle = m.saveDialog.PlaceholderStyle
+	m.jumpDialog.TextStyle = m.saveDialog.TextStyle
 
 	m.setContentHeight()
 	m.keyMap = GetKeyMap()
 	m.cursorEnabled = true
 	m.wrapText = false
 	m.mouseWheelEnabled = false
+	m.marked = make(map[int]bool)
+	m.markAnchor = -1
 	m.HeaderStyle = style.ViewportHeaderStyle
-	m.CursorRowStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("6"))
-	m.HighlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#e760fc"))
-	m.FooterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#737373"))
+	m.CursorRowStyle = style.CursorRow
+	m.MarkedRowStyle = style.MarkedRow
+	m.HighlightStyle = style.Highlight
+	m.FooterStyle = style.Footer
 	return m
 }
 
@@ -96,17 +139,32 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		case tea.KeyMsg:
 			switch {
 			case key.Matches(msg, m.keyMap.CancelSave):
-				m.saveDialog.Blur()
-				m.saveDialog.Reset()
+				m.resetSaveDialog()
 
 			case key.Matches(msg, m.keyMap.ConfirmSave):
-				var content string
-				for _, line := range append(m.header, m.content...) {
-					content += strings.TrimRight(line, " ") + "\n"
+				saveRequest := SaveRequestMsg{FileName: m.saveDialog.Value(), AllRows: m.saveAllRows}
+				cmds = append(cmds, func() tea.Msg { return saveRequest })
+				m.resetSaveDialog()
+
+			case key.Matches(msg, m.keyMap.ToggleSaveRows):
+				m.setSaveAllRows(!m.saveAllRows)
+			}
+		}
+	} else if m.jumpDialog.Focused() {
+		m.jumpDialog, cmd = m.jumpDialog.Update(msg)
+		cmds = append(cmds, cmd)
+
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch {
+			case key.Matches(msg, m.keyMap.CancelSave):
+				m.resetJumpDialog()
+
+			case key.Matches(msg, m.keyMap.ConfirmSave):
+				if err := m.jump(m.jumpDialog.Value()); err != nil {
+					cmds = append(cmds, func() tea.Msg { return JumpErrorMsg{Err: err.Error()} })
 				}
-				cmds = append(cmds, saveCommand(m.saveDialog.Value(), content))
-				m.saveDialog.Blur()
-				m.saveDialog.Reset()
+				m.resetJumpDialog()
 			}
 		}
 	} else {
@@ -173,7 +231,42 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 			case key.Matches(msg, m.keyMap.Save):
 				m.saveDialog.Focus()
+				m.setSaveAllRows(false)
+				cmds = append(cmds, textinput.Blink)
+
+			case key.Matches(msg, m.keyMap.Jump):
+				m.jumpDialog.Focus()
 				cmds = append(cmds, textinput.Blink)
+
+			case key.Matches(msg, m.keyMap.Mark) && m.cursorEnabled:
+				if m.markAnchor >= 0 {
+					m.SetMarkedRows(m.MarkedRows())
+				} else {
+					m.marked[m.cursorRow] = !m.marked[m.cursorRow]
+					if !m.marked[m.cursorRow] {
+						delete(m.marked, m.cursorRow)
+					}
+				}
+				m.marksChanged()
+
+			case key.Matches(msg, m.keyMap.MarkRange) && m.cursorEnabled:
+				if m.markAnchor >= 0 {
+					m.SetMarkedRows(m.MarkedRows())
+				} else {
+					m.markAnchor = m.cursorRow
+				}
+				m.marksChanged()
+
+			case key.Matches(msg, m.keyMap.MarkUp, m.keyMap.MarkDown) && m.cursorEnabled:
+				if m.markAnchor < 0 {
+					m.markAnchor = m.cursorRow
+				}
+				if key.Matches(msg, m.keyMap.MarkUp) {
+					m.cursorRowUp(1)
+				} else {
+					m.cursorRowDown(1)
+				}
+				m.marksChanged()
 			}
 
 		case tea.MouseMsg:
@@ -213,23 +306,32 @@ func (m Model) View() string {
 		}
 	}
 
+	markedRows := make(map[int]bool)
+	for _, row := range m.MarkedRows() {
+		markedRows[row] = true
+	}
+
 	for idx, line := range m.visibleLines() {
 		isSelected := m.cursorEnabled && m.yOffset+idx == m.cursorRow
 		parsedLines := m.lineToViewLines(line)
+		contentStyle := m.getContentStyle(m.yOffset + idx)
+		if markedRows[m.yOffset+idx] {
+			contentStyle = m.MarkedRowStyle
+		}
 
 		if nothingHighlighted {
 			for _, line := range parsedLines {
 				if isSelected {
 					addLineToViewString(m.CursorRowStyle.Render(line))
 				} else {
-					addLineToViewString(m.ContentStyle.Render(line))
+					addLineToViewString(contentStyle.Render(line))
 				}
 			}
 		} else {
 			// this splitting and rejoining of styled content is expensive and causes increased flickering,
 			// so only do it if something is actually highlighted
 			styledHighlight := m.HighlightStyle.Render(m.Highlight)
-			lineStyle := m.ContentStyle
+			lineStyle := contentStyle
 			if isSelected {
 				lineStyle = m.CursorRowStyle
 			}
@@ -284,16 +386,24 @@ func (m *Model) SetHeader(header []string) {
 	m.fixState()
 }
 
+// SetContent sets the lines of content, clearing any marks as the lines they marked may have changed
 func (m *Model) SetContent(content []string) {
 	m.content = content
+	m.marked = make(map[int]bool)
+	m.markAnchor = -1
 	m.updateMaxLineLength()
 	m.setContentHeight()
 	m.fixState()
 }
 
+// SetContentStyles sets styles for individual content lines by index. Lines without an entry use ContentStyle.
+func (m *Model) SetContentStyles(contentStyles map[int]lipgloss.Style) {
+	m.contentStyles = contentStyles
+}
+
 func (m *Model) updateMaxLineLength() {
 	for _, line := range append(m.header, m.content...) {
-		if lineLength := len(strings.TrimRight(line, " ")); lineLength > m.maxLineLength {
+		if lineLength := utf8.RuneCountInString(strings.TrimRight(line, " ")); lineLength > m.maxLineLength {
 			m.maxLineLength = lineLength
 		}
 	}
@@ -322,22 +432,168 @@ func (m Model) CursorRow() int {
 	return m.cursorRow
 }
 
+// CurrentLine returns the index of the cursor's line, or of the top visible line if the cursor is disabled
+func (m Model) CurrentLine() int {
+	if m.cursorEnabled {
+		return m.cursorRow
+	}
+	return m.yOffset
+}
+
+// GoToLine moves the cursor to the line at index n, or scrolls it to the top if the cursor is disabled
+func (m *Model) GoToLine(n int) {
+	if m.cursorEnabled {
+		m.SetCursorRow(n)
+	} else {
+		m.setYOffset(n)
+	}
+}
+
+// jump goes to the line number or time typed in the jump dialog. Lines are searched for a time assuming they're in
+// time order, like logs, with lines that don't start with a time taking the time of the line above.
+func (m *Model) jump(target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil
+	}
+	if lineNumber, err := strconv.Atoi(target); err == nil {
+		if lineNumber < 1 || lineNumber > len(m.content) {
+			return fmt.Errorf("no line %d, there are %d lines", lineNumber, len(m.content))
+		}
+		m.GoToLine(lineNumber - 1)
+		return nil
+	}
+
+	near, ok := m.lineTime(m.CurrentLine())
+	if !ok {
+		for idx := range m.content {
+			if near, ok = formatter.ParseLogLineTime(m.content[idx]); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return fmt.Errorf("can't go to %q, no lines start with a time", target)
+	}
+	t, ok := formatter.ParseJumpTime(target, near)
+	if !ok {
+		return fmt.Errorf("%q is not a line number or time", target)
+	}
+	m.GoToLine(sort.Search(len(m.content), func(idx int) bool {
+		lineTime, ok := m.lineTime(idx)
+		return ok && !lineTime.Before(t)
+	}))
+	return nil
+}
+
+// lineTime returns the time at the start of the line at index idx, or of the closest line above it that has one
+func (m Model) lineTime(idx int) (time.Time, bool) {
+	for ; idx >= 0 && idx < len(m.content); idx-- {
+		if t, ok := formatter.ParseLogLineTime(m.content[idx]); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MarkedRows returns the sorted indices of marked content lines, including the range being marked
+func (m Model) MarkedRows() []int {
+	var rows []int
+	for row := range m.marked {
+		rows = append(rows, row)
+	}
+	if m.markAnchor >= 0 {
+		for row := min(m.markAnchor, m.cursorRow); row <= max(m.markAnchor, m.cursorRow); row++ {
+			if !m.marked[row] {
+				rows = append(rows, row)
+			}
+		}
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// SetMarkedRows marks the content lines at indices rows, and stops marking a range
+func (m *Model) SetMarkedRows(rows []int) {
+	m.marked = make(map[int]bool)
+	for _, row := range rows {
+		if row >= 0 && row < len(m.content) {
+			m.marked[row] = true
+		}
+	}
+	m.markAnchor = -1
+	m.marksChanged()
+}
+
+func (m *Model) ClearMarks() {
+	m.SetMarkedRows(nil)
+}
+
+// marksChanged updates the content height, as the footer shows the number of marked lines
+func (m *Model) marksChanged() {
+	m.setContentHeight()
+	m.fixState()
+}
+
 func (m Model) Saving() bool {
 	return m.saveDialog.Focused()
 }
 
+func (m Model) Jumping() bool {
+	return m.jumpDialog.Focused()
+}
+
+func (m *Model) resetJumpDialog() {
+	m.jumpDialog.Blur()
+	m.jumpDialog.Reset()
+}
+
+func (m *Model) resetSaveDialog() {
+	m.saveDialog.Blur()
+	m.saveDialog.Reset()
+	m.setSaveAllRows(false)
+}
+
+// setSaveAllRows sets whether rows hidden by the filter are saved, rather than the marked rows if any or the rows
+// matching the filter, shown by the save dialog's prompt
+func (m *Model) setSaveAllRows(saveAllRows bool) {
+	m.saveAllRows = saveAllRows
+	switch {
+	case saveAllRows:
+		m.saveDialog.Prompt = saveAllRowsPrompt
+	case len(m.MarkedRows()) > 0:
+		m.saveDialog.Prompt = saveMarkedRowsPrompt
+	default:
+		m.saveDialog.Prompt = savePrompt
+	}
+	m.saveDialog.Placeholder = m.getSaveDialogPlaceholder()
+}
+
 func (m *Model) setWidthAndHeight(width, height int) {
 	m.width, m.height = width, height
 	m.saveDialog.Placeholder = m.getSaveDialogPlaceholder()
+	m.jumpDialog.Placeholder = m.getDialogPlaceholder(constants.JumpDialogPlaceholder, jumpPrompt)
 }
 
 func (m Model) getSaveDialogPlaceholder() string {
-	padding := m.width - utf8.RuneCountInString(constants.SaveDialogPlaceholder) - utf8.RuneCountInString(m.saveDialog.Prompt)
+	return m.getDialogPlaceholder(constants.SaveDialogPlaceholder, m.saveDialog.Prompt)
+}
+
+// getDialogPlaceholder pads placeholder to fill the width of a dialog with prompt
+func (m Model) getDialogPlaceholder(placeholder, prompt string) string {
+	padding := m.width - utf8.RuneCountInString(placeholder) - utf8.RuneCountInString(prompt)
 	padding = max(0, padding)
-	placeholder := constants.SaveDialogPlaceholder + strings.Repeat(" ", padding)
+	placeholder += strings.Repeat(" ", padding)
 	return placeholder[:min(len(placeholder), m.width)]
 }
 
+func (m Model) getContentStyle(lineIdx int) lipgloss.Style {
+	if contentStyle, exists := m.contentStyles[lineIdx]; exists {
+		return contentStyle
+	}
+	return m.ContentStyle
+}
+
 // func normalizeLineEndings(s string) string {
 // 	return strings.ReplaceAll(s, "\r\n", "\n")
 // }
@@ -445,17 +701,19 @@ func (m *Model) viewRight(n int) {
 }
 
 func (m Model) getVisiblePartOfLine(line string) string {
-	rightTrimmedLineLength := len(strings.TrimRight(line, " "))
-	end := min(len(line), m.xOffset+m.width)
+	// work in runes so multi-byte characters, e.g. sparklines, aren't split
+	lineRunes := []rune(line)
+	rightTrimmedLineLength := utf8.RuneCountInString(strings.TrimRight(line, " "))
+	end := min(len(lineRunes), m.xOffset+m.width)
 	start := min(end, m.xOffset)
-	line = line[start:end]
+	lineRunes = lineRunes[start:end]
 	if m.xOffset+m.width < rightTrimmedLineLength {
-		line = line[:len(line)-lenLineContinuationIndicator] + lineContinuationIndicator
+		lineRunes = append(lineRunes[:max(0, len(lineRunes)-lenLineContinuationIndicator)], []rune(lineContinuationIndicator)...)
 	}
 	if m.xOffset > 0 {
-		line = lineContinuationIndicator + line[min(len(line), lenLineContinuationIndicator):]
+		lineRunes = append([]rune(lineContinuationIndicator), lineRunes[min(len(lineRunes), lenLineContinuationIndicator):]...)
 	}
-	return line
+	return string(lineRunes)
 }
 
 func (m Model) getWrappedLines(line string) []string {
@@ -490,6 +748,9 @@ func (m Model) getFooter() (string, int) {
 	if m.saveDialog.Focused() {
 		return lipgloss.NewStyle().MaxWidth(m.width).Render(m.saveDialog.View()), 1
 	}
+	if m.jumpDialog.Focused() {
+		return lipgloss.NewStyle().MaxWidth(m.width).Render(m.jumpDialog.View()), 1
+	}
 
 	// if cursor is disabled, percentage should show from the bottom of the visible content
 	// such that panning the view to the bottom shows 100%
@@ -497,9 +758,16 @@ func (m Model) getFooter() (string, int) {
 		numerator = m.yOffset + len(m.visibleLines())
 	}
 
+	var footerParts []string
 	if numLines := len(m.content); numLines > m.height-len(m.header) {
 		percentScrolled := percent(numerator, numLines)
-		footerString := fmt.Sprintf("%d%% (%d/%d)", percentScrolled, numerator, numLines)
+		footerParts = append(footerParts, fmt.Sprintf("%d%% (%d/%d)", percentScrolled, numerator, numLines))
+	}
+	if numMarked := len(m.MarkedRows()); numMarked > 0 {
+		footerParts = append(footerParts, fmt.Sprintf("%d marked", numMarked))
+	}
+	if len(footerParts) > 0 {
+		footerString := strings.Join(footerParts, "    ")
 		renderedFooterString := m.FooterStyle.Copy().MaxWidth(m.width).Render(footerString)
 		footerHeight := lipgloss.Height(renderedFooterString)
 		return renderedFooterString, footerHeight
@@ -507,17 +775,6 @@ func (m Model) getFooter() (string, int) {
 	return "", 0
 }
 
-func saveCommand(saveDialogValue string, fileContent string) tea.Cmd {
-	return func() tea.Msg {
-		savePathWithFileName, err := fileio.SaveToFile(saveDialogValue, fileContent)
-		if err != nil {
-			return SaveStatusMsg{SuccessMessage: "", Err: err.Error()}
-		}
-		successMessage := fmt.Sprintf("Success: saved to %s", savePathWithFileName)
-		return SaveStatusMsg{SuccessMessage: successMessage, Err: ""}
-	}
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,136 @@
+package viewport
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"reflect"
+	"testing"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func newTestViewport(lines int) Model {
+	m := New(80, 20)
+	var content []string
+	for i := 0; i < lines; i++ {
+		content = append(content, "line")
+	}
+	m.SetContent(content)
+	return m
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		m, _ = m.Update(runes(k))
+	}
+	return m
+}
+
+func assertMarked(t *testing.T, m Model, expected []int) {
+	t.Helper()
+	if actual := m.MarkedRows(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected rows %v marked, got %v", expected, actual)
+	}
+}
+
+func TestMark(t *testing.T) {
+	m := press(newTestViewport(5), "x", "j", "j", "x")
+	assertMarked(t, m, []int{0, 2})
+
+	m = press(m, "k", "k", "x")
+	assertMarked(t, m, []int{2})
+}
+
+func TestMarkRange(t *testing.T) {
+	m := press(newTestViewport(5), "j", "v", "j", "j")
+	assertMarked(t, m, []int{1, 2, 3})
+
+	// moving back past the start of the range extends it the other way
+	m = press(m, "k", "k", "k")
+	assertMarked(t, m, []int{0, 1})
+
+	// ending the range keeps its rows marked as the cursor moves on
+	m = press(m, "v", "j", "j", "j")
+	assertMarked(t, m, []int{0, 1})
+}
+
+func TestMarkUpAndDown(t *testing.T) {
+	m := press(newTestViewport(5), "x", "j", "j", "J", "J")
+	assertMarked(t, m, []int{0, 2, 3, 4})
+
+	// the first mark ends the range, the second unmarks the cursor row
+	m = press(m, "x", "x")
+	assertMarked(t, m, []int{0, 2, 3})
+}
+
+func TestMarksClearedWithContent(t *testing.T) {
+	m := press(newTestViewport(5), "v", "j")
+	m.SetContent([]string{"other"})
+	assertMarked(t, m, nil)
+}
+
+func TestMarksNeedCursor(t *testing.T) {
+	m := newTestViewport(5)
+	m.SetCursorEnabled(false)
+	m = press(m, "x", "v", "J")
+	assertMarked(t, m, nil)
+}
+
+// jumpTo types target into the jump dialog and confirms it
+func jumpTo(m Model, target string) Model {
+	m = press(m, ":")
+	for _, r := range target {
+		m = press(m, string(r))
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return m
+}
+
+func TestJumpToLine(t *testing.T) {
+	m := jumpTo(newTestViewport(50), "30")
+	if m.CurrentLine() != 29 || m.Jumping() {
+		t.Errorf("expected cursor on line index 29 with dialog closed, got %d", m.CurrentLine())
+	}
+
+	if err := m.jump("51"); err == nil {
+		t.Error("expected an error for a line past the end")
+	}
+
+	m.SetCursorEnabled(false)
+	m = jumpTo(m, "10")
+	if m.CurrentLine() != 9 {
+		t.Errorf("expected view scrolled to line index 9, got %d", m.CurrentLine())
+	}
+}
+
+func TestJumpToTime(t *testing.T) {
+	m := New(80, 20)
+	m.SetContent([]string{
+		"starting",
+		"2022-06-01T11:59:00Z first",
+		"2022-06-01T12:00:00Z second",
+		"  continued",
+		"2022-06-01T12:00:30Z third",
+		"2022-06-01T12:05:00Z fourth",
+	})
+
+	for target, expected := range map[string]int{
+		"12:00:10":         4,
+		"12:00":            2,
+		"2022-06-01T11:00": 1,
+		"2022-06-02":       5,
+	} {
+		if actual := jumpTo(m, target); actual.CurrentLine() != expected {
+			t.Errorf("%s: expected line index %d, got %d", target, expected, actual.CurrentLine())
+		}
+	}
+
+	if err := m.jump("noon"); err == nil {
+		t.Error("expected an error for something that isn't a line number or time")
+	}
+	lines := newTestViewport(5)
+	if err := lines.jump("12:00"); err == nil {
+		t.Error("expected an error for a time in lines without times")
+	}
+}
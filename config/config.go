@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"wander/constants"
+)
+
+// Config is wander's config file, e.g.
+//
+//	{"keys": {"global": {"StdErr": ["ctrl+e"]}}, "theme": "light"}
+type Config struct {
+	// Keys maps a keymap name, e.g. "global", "viewport" or "prompt", to the names of its bindings and the keys that
+	// trigger them instead of the defaults
+	Keys map[string]map[string][]string `json:"keys"`
+
+	// Theme names a built-in theme, "dark", "light", "high-contrast" or "monochrome", or one of Themes. By default,
+	// dark or light is picked to suit the terminal's background.
+	Theme string `json:"theme"`
+
+	// Themes are user themes by name
+	Themes map[string]Theme `json:"themes"`
+}
+
+// Theme is a user theme, changing colors of a built-in theme
+type Theme struct {
+	// Base names the built-in theme, "dark" by default
+	Base string `json:"base"`
+	// Colors maps a color's name, e.g. "Cursor", to its new value, e.g. "#00AFFF"
+	Colors map[string]string `json:"colors"`
+}
+
+// Path returns the config file's path, set in the environment or in the user's config directory
+func Path() string {
+	if path := os.Getenv(constants.ConfigEnvVariable); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "wander", "config.json")
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		return config, nil
+	}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return config, nil
+}
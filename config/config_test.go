@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	config, err := Load(writeConfig(t, `{"keys": {"global": {"StdErr": ["E"], "Exit": []}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]map[string][]string{"global": {"StdErr": {"E"}, "Exit": {}}}
+	if !reflect.DeepEqual(config.Keys, expected) {
+		t.Errorf("expected %v, got %v", expected, config.Keys)
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	config, err := Load(writeConfig(t, `{"theme": "mine", "themes": {"mine": {"base": "light", "colors": {"Cursor": "6"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Theme{"mine": {Base: "light", Colors: map[string]string{"Cursor": "6"}}}
+	if config.Theme != "mine" || !reflect.DeepEqual(config.Themes, expected) {
+		t.Errorf("expected theme mine of %v, got %s of %v", expected, config.Theme, config.Themes)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	config, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || config.Keys != nil {
+		t.Errorf("expected an empty config, got %v, %v", config, err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, contents := range []string{`{"keys": `, `{"kyes": {}}`, `{"keys": {"global": {"StdErr": "E"}}}`} {
+		if _, err := Load(writeConfig(t, contents)); err == nil {
+			t.Errorf("expected an error for %s", contents)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("WANDER_CONFIG", "/tmp/wander.json")
+	if actual := Path(); actual != "/tmp/wander.json" {
+		t.Errorf("expected path from environment, got %s", actual)
+	}
+}
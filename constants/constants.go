@@ -6,10 +6,18 @@ import (
 )
 
 const (
-	NomadTokenEnvVariable = "NOMAD_TOKEN"
-	NomadUrlEnvVariable   = "NOMAD_ADDR"
+	NomadTokenEnvVariable   = "NOMAD_TOKEN"
+	NomadUrlEnvVariable     = "NOMAD_ADDR"
+	NomadRegionEnvVariable  = "NOMAD_REGION"
+	LogTailBytesEnvVariable = "WANDER_LOG_TAIL_BYTES"
+	StartPageEnvVariable    = "WANDER_START_PAGE"
+	ConfigEnvVariable       = "WANDER_CONFIG"
+	NoColorEnvVariable      = "NO_COLOR"
 )
 
+// DefaultLogTailBytes is the number of bytes loaded from the end of logs at a time
+const DefaultLogTailBytes = 1000000
+
 var LogoString = strings.Join([]string{
 	"█ █ █ █▀█ █▄ █ █▀▄ █▀▀ █▀█",
 	"▀▄▀▄▀ █▀█ █ ▀█ █▄▀ ██▄ █▀▄",
@@ -17,4 +25,18 @@ var LogoString = strings.Join([]string{
 
 const ToastDuration = time.Second * 5
 
-const SaveDialogPlaceholder = "Output file name (path optional)"
+const SaveDialogPlaceholder = "Output file name (path optional, .csv/.tsv/.json/.md to save the table)"
+
+const JumpDialogPlaceholder = "Line number, or time in logs (e.g. 1234, 12:00:30, 2022-06-01T12:00)"
+
+const (
+	StatsPollInterval      = time.Second * 2
+	StatsHistoryLength     = 30
+	StatsHighMemoryPercent = 90
+)
+
+// MaxTemplateBytes is the most of each rendered template read on the templates page
+const MaxTemplateBytes = 1000000
+
+// ConcurrentServiceRequests is how many services' instances are counted at once on the services page
+const ConcurrentServiceRequests = 8
@@ -2,7 +2,9 @@ package formatter
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"github.com/olekukonko/tablewriter"
 	"strings"
 	"time"
@@ -72,6 +74,42 @@ func GetRenderedTableAsString(columns []string, data [][]string) Table {
 	return Table{headerRows, contentRows}
 }
 
+// TableAsDelimited renders a table with a header row of columns, separating cells with delimiter, e.g. ',' for csv
+func TableAsDelimited(columns []string, data [][]string, delimiter rune) (string, error) {
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+	writer.Comma = delimiter
+	if err := writer.Write(columns); err != nil {
+		return "", err
+	}
+	if err := writer.WriteAll(data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// TableAsMarkdown renders a table in markdown, escaping pipes in cells
+func TableAsMarkdown(columns []string, data [][]string) string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		b.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	}
+	writeRow(columns)
+	separator := make([]string, len(columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range data {
+		writeRow(row)
+	}
+	return b.String()
+}
+
 func ShortAllocID(allocID string) string {
 	firstN := 8
 	if len(allocID) < firstN {
@@ -96,3 +134,98 @@ func FormatTimeNs(t int64) string {
 	tm := time.Unix(0, t).UTC()
 	return FormatTime(tm)
 }
+
+var logLineTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+}
+
+// ParseLogLineTime parses a timestamp at the start of a log line, e.g. "2022-06-01T12:00:00Z GET /health".
+// Surrounding brackets are ignored. Returns false if the line does not start with a known timestamp format.
+func ParseLogLineTime(line string) (time.Time, bool) {
+	fields := strings.Fields(strings.TrimLeft(line, "["))
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+
+	candidates := []string{fields[0]}
+	if len(fields) > 1 {
+		// date and time separated by a space
+		candidates = append(candidates, fields[0]+" "+fields[1])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimRight(candidate, "],")
+		for _, layout := range logLineTimeLayouts {
+			if t, err := time.Parse(layout, candidate); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseJumpTime parses a time typed to jump to in a log, e.g. "2022-06-01T12:00" or "12:00:30", in the location of
+// near, a time from the log. Times without a date are taken to be on the same day as near.
+func ParseJumpTime(s string, near time.Time) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	layouts := append([]string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}, logLineTimeLayouts...)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, near.Location()); err == nil {
+			return t, true
+		}
+	}
+	for _, layout := range []string{"15:04:05.999999999", "15:04"} {
+		if t, err := time.ParseInLocation(layout, s, near.Location()); err == nil {
+			year, month, day := near.Date()
+			return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), near.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FormatBytes formats a number of bytes in human-readable binary units, e.g. 1536 -> "1.5 KiB"
+func FormatBytes(numBytes int64) string {
+	const unit = 1024
+	if numBytes < unit {
+		return fmt.Sprintf("%d B", numBytes)
+	}
+	div, exp := int64(unit), 0
+	for n := numBytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(numBytes)/float64(div), "KMGTPE"[exp])
+}
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a single line of block characters scaled between 0 and max. Values above max are
+// shown as full blocks. If max is not positive, values are scaled to the largest value.
+func Sparkline(values []float64, max float64) string {
+	if max <= 0 {
+		for _, value := range values {
+			if value > max {
+				max = value
+			}
+		}
+	}
+
+	var sparkline []rune
+	for _, value := range values {
+		idx := 0
+		if max > 0 {
+			idx = int(value / max * float64(len(sparklineBlocks)-1))
+		}
+		if idx < 0 {
+			idx = 0
+		} else if idx >= len(sparklineBlocks) {
+			idx = len(sparklineBlocks) - 1
+		}
+		sparkline = append(sparkline, sparklineBlocks[idx])
+	}
+	return string(sparkline)
+}
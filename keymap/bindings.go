@@ -0,0 +1,86 @@
+package keymap
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"reflect"
+	"strings"
+)
+
+// The names of the keymaps whose bindings can be overridden
+const (
+	Global   = "global"
+	Viewport = "viewport"
+	Prompt   = "prompt"
+)
+
+// letterSuffix is shown after the keys of bindings that are followed by a letter, e.g. to name a bookmark
+const letterSuffix = "<letter>"
+
+// overrides maps a keymap name to binding names and the keys configured for them
+var overrides map[string]map[string][]string
+
+// NamedBinding is a binding in a keymap, named by its field, e.g. "HalfPageDown"
+type NamedBinding struct {
+	Name string
+	*key.Binding
+}
+
+// Bindings returns the bindings of keyMap, a pointer to a struct of key.Bindings, in field order
+func Bindings(keyMap interface{}) []NamedBinding {
+	var bindings []NamedBinding
+	v := reflect.ValueOf(keyMap).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if binding, ok := v.Field(i).Addr().Interface().(*key.Binding); ok {
+			bindings = append(bindings, NamedBinding{Name: v.Type().Field(i).Name, Binding: binding})
+		}
+	}
+	return bindings
+}
+
+// SetOverrides sets the keys configured for bindings by keymap name then binding name, and applies them to KeyMap.
+// An empty list of keys disables a binding.
+func SetOverrides(keys map[string]map[string][]string) {
+	overrides = keys
+	KeyMap = defaultKeyMap()
+	Override(Global, &KeyMap)
+}
+
+// Override sets the keys and help of bindings in keyMap, a pointer to a struct of key.Bindings, to those configured
+// for the keymap called name
+func Override(name string, keyMap interface{}) {
+	for _, binding := range Bindings(keyMap) {
+		keys, configured := overrides[name][binding.Name]
+		if !configured {
+			continue
+		}
+		helpKey := HelpKey(keys)
+		if strings.HasSuffix(binding.Help().Key, letterSuffix) {
+			helpKey += letterSuffix
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(helpKey, binding.Help().Desc)
+	}
+}
+
+// HelpKey returns how keys are shown in help, e.g. "↓/j"
+func HelpKey(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		case "left":
+			k = "←"
+		case "right":
+			k = "→"
+		case "pgdown":
+			k = "pgdn"
+		case " ":
+			k = "space"
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
@@ -5,47 +5,187 @@ import (
 )
 
 type keyMap struct {
-	Exit    key.Binding
-	Forward key.Binding
-	Back    key.Binding
-	Reload  key.Binding
-	Filter  key.Binding
-	StdOut  key.Binding
-	StdErr  key.Binding
-	Spec    key.Binding
+	Exit         key.Binding
+	Forward      key.Binding
+	Back         key.Binding
+	Reload       key.Binding
+	Filter       key.Binding
+	StdOut       key.Binding
+	StdErr       key.Binding
+	StdOutAndErr key.Binding
+	Spec         key.Binding
+	Files        key.Binding
+	Templates    key.Binding
+	Stats        key.Binding
+	Nodes        key.Binding
+	Drain        key.Binding
+	Eligibility  key.Binding
+	Services     key.Binding
+	Volumes      key.Binding
+	Plugins      key.Binding
+	Detach       key.Binding
+	Token        key.Binding
+	Overview     key.Binding
+	Dispatch     key.Binding
+	Expand       key.Binding
+	TaskGroups   key.Binding
+	Scale        key.Binding
+	Region       key.Binding
+	Datacenter   key.Binding
+	CopyID       key.Binding
+	CopyRow      key.Binding
+	CopyView     key.Binding
+	Restart      key.Binding
+	Stop         key.Binding
+	SetBookmark  key.Binding
+	GoToBookmark key.Binding
+	Help         key.Binding
 }
 
-var KeyMap = keyMap{
-	Exit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "exit"),
-	),
-	Forward: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "enter"),
-	),
-	Back: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "back"),
-	),
-	Reload: key.NewBinding(
-		key.WithKeys("r"),
-		key.WithHelp("r", "reload"),
-	),
-	Filter: key.NewBinding(
-		key.WithKeys("/"),
-		key.WithHelp("/", "filter"),
-	),
-	StdOut: key.NewBinding(
-		key.WithKeys("o"),
-		key.WithHelp("o", "stdout"),
-	),
-	StdErr: key.NewBinding(
-		key.WithKeys("e"),
-		key.WithHelp("e", "stderr"),
-	),
-	Spec: key.NewBinding(
-		key.WithKeys("p"),
-		key.WithHelp("p", "view spec"),
-	),
+// KeyMap is the global keymap, with any keys overridden by SetOverrides
+var KeyMap = defaultKeyMap()
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Exit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "exit"),
+		),
+		Forward: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "enter"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reload"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		StdOut: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "stdout"),
+		),
+		StdErr: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "stderr"),
+		),
+		StdOutAndErr: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "stdout+stderr"),
+		),
+		Spec: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "view spec"),
+		),
+		Files: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "browse files"),
+		),
+		Templates: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "templates & env"),
+		),
+		Stats: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "resource usage"),
+		),
+		Nodes: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "nodes"),
+		),
+		Drain: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "toggle drain"),
+		),
+		Eligibility: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "toggle eligibility"),
+		),
+		Services: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "services"),
+		),
+		Volumes: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "volumes"),
+		),
+		Plugins: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "plugin health"),
+		),
+		Detach: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "detach claim"),
+		),
+		Token: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "acl token"),
+		),
+		Overview: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "cluster overview"),
+		),
+		Dispatch: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "dispatch job"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "expand/collapse children"),
+		),
+		TaskGroups: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "task groups"),
+		),
+		Scale: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "scale"),
+		),
+		Region: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "region"),
+		),
+		Datacenter: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "datacenter"),
+		),
+		CopyID: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy id"),
+		),
+		CopyRow: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy row"),
+		),
+		CopyView: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "copy view"),
+		),
+		Restart: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "restart"),
+		),
+		Stop: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "stop"),
+		),
+		SetBookmark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m<letter>", "set bookmark"),
+		),
+		GoToBookmark: key.NewBinding(
+			key.WithKeys("'"),
+			key.WithHelp("'<letter>", "go to bookmark"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+	}
 }
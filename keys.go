@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"wander/components/prompt"
+	"wander/components/viewport"
+	"wander/keymap"
+	"wander/nomad"
+)
+
+// configurableKeyMap is a keymap whose bindings can be overridden in the config file
+type configurableKeyMap struct {
+	name     string
+	bindings []keymap.NamedBinding
+}
+
+// configurableKeyMaps returns the keymaps that can be configured, with their effective keys
+func configurableKeyMaps() []configurableKeyMap {
+	viewportKeyMap, promptKeyMap := viewport.GetKeyMap(), prompt.GetKeyMap()
+	return []configurableKeyMap{
+		{keymap.Global, keymap.Bindings(&keymap.KeyMap)},
+		{keymap.Viewport, keymap.Bindings(&viewportKeyMap)},
+		{keymap.Prompt, keymap.Bindings(&promptKeyMap)},
+	}
+}
+
+// dialogBindings are the viewport bindings only used while the save or jump dialog is open
+var dialogBindings = map[string]bool{"CancelSave": true, "ConfirmSave": true, "ToggleSaveRows": true}
+
+// Bindings are checked for conflicts with the others in their scope, those that can be pressed at the same time
+const (
+	pageScope   = "page"
+	dialogScope = "dialog"
+	promptScope = "prompt"
+)
+
+// keyScopes returns the scopes a binding is active in. Exit is always active.
+func keyScopes(keyMapName, bindingName string) []string {
+	switch {
+	case keyMapName == keymap.Global && bindingName == "Exit":
+		return []string{pageScope, dialogScope, promptScope}
+	case keyMapName == keymap.Prompt:
+		return []string{promptScope}
+	case keyMapName == keymap.Viewport && dialogBindings[bindingName]:
+		return []string{dialogScope}
+	}
+	return []string{pageScope}
+}
+
+// applyKeyConfig overrides the keys of bindings with those in the config file, returning an error if it names
+// bindings that don't exist or leaves two bindings that are active at the same time with the same key
+func applyKeyConfig(keys map[string]map[string][]string) error {
+	known := make(map[string]map[string]bool)
+	for _, keyMap := range configurableKeyMaps() {
+		known[keyMap.name] = make(map[string]bool)
+		for _, binding := range keyMap.bindings {
+			known[keyMap.name][binding.Name] = true
+		}
+	}
+	for keyMapName, bindings := range keys {
+		if known[keyMapName] == nil {
+			return fmt.Errorf("unknown keymap %q in config, must be one of: %s, %s, %s", keyMapName, keymap.Global, keymap.Viewport, keymap.Prompt)
+		}
+		for bindingName := range bindings {
+			if !known[keyMapName][bindingName] {
+				return fmt.Errorf("unknown key binding %s.%s in config, see `wander keys`", keyMapName, bindingName)
+			}
+		}
+	}
+
+	keymap.SetOverrides(keys)
+	if conflicts := keyConflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("conflicting key bindings in config:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	return nil
+}
+
+// keyConflicts describes each key bound to more than one binding in the same scope
+func keyConflicts() []string {
+	boundTo := make(map[string]map[string][]string)
+	for _, keyMap := range configurableKeyMaps() {
+		for _, binding := range keyMap.bindings {
+			if !binding.Enabled() {
+				continue
+			}
+			for _, scope := range keyScopes(keyMap.name, binding.Name) {
+				if boundTo[scope] == nil {
+					boundTo[scope] = make(map[string][]string)
+				}
+				for _, k := range binding.Keys() {
+					boundTo[scope][k] = append(boundTo[scope][k], keyMap.name+"."+binding.Name)
+				}
+			}
+		}
+	}
+
+	var conflicts []string
+	for _, scope := range []string{pageScope, dialogScope, promptScope} {
+		var keys []string
+		for k, names := range boundTo[scope] {
+			if len(names) > 1 {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			conflicts = append(conflicts, fmt.Sprintf("%q is bound to %s", k, strings.Join(boundTo[scope][k], " and ")))
+		}
+	}
+	return conflicts
+}
+
+// keyBindingsTable lists the effective key bindings, with the names they're configured by
+func keyBindingsTable() nomad.Table {
+	table := nomad.Table{Columns: []string{"Keymap", "Binding", "Keys", "Help"}}
+	for _, keyMap := range configurableKeyMaps() {
+		for _, binding := range keyMap.bindings {
+			keys := "(disabled)"
+			if binding.Enabled() {
+				quoted := make([]string, len(binding.Keys()))
+				for i, k := range binding.Keys() {
+					quoted[i] = strconv.Quote(k)
+				}
+				keys = strings.Join(quoted, ", ")
+			}
+			table.Rows = append(table.Rows, []string{keyMap.name, binding.Name, keys, binding.Help().Desc})
+		}
+	}
+	return table
+}
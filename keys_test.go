@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"wander/components/viewport"
+	"wander/keymap"
+	"wander/nomad"
+	"wander/nomad/nomadtest"
+)
+
+// withKeys applies keys as the key config until the test ends
+func withKeys(t *testing.T, keys map[string]map[string][]string) error {
+	t.Cleanup(func() { keymap.SetOverrides(nil) })
+	return applyKeyConfig(keys)
+}
+
+func TestDefaultKeysDontConflict(t *testing.T) {
+	if conflicts := keyConflicts(); len(conflicts) > 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+}
+
+func TestKeyConfig(t *testing.T) {
+	err := withKeys(t, map[string]map[string][]string{
+		keymap.Global:   {"StdErr": {"ctrl+e"}, "Exit": {"ctrl+c"}},
+		keymap.Viewport: {"HalfPageDown": {"ctrl+d"}, "PageDown": {"pgdown", " "}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keymap.KeyMap.StdErr.Help().Key != "ctrl+e" || keymap.KeyMap.StdErr.Help().Desc != "stderr" {
+		t.Errorf("expected stderr help overridden, got %v", keymap.KeyMap.StdErr.Help())
+	}
+	if keys := viewport.GetKeyMap().PageDown.Keys(); len(keys) != 2 || keys[1] != " " {
+		t.Errorf("expected viewport keys overridden, got %q", keys)
+	}
+
+	help := nomad.GetPageKeyHelp(nomad.LogsPage, nomad.Permissions{}, 1000)
+	for _, expected := range []string{"ctrl+e", "pgdn/space"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected %q in help, got %s", expected, help)
+		}
+	}
+}
+
+func TestKeyConfigQuits(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	if err := withKeys(t, map[string]map[string][]string{keymap.Global: {"Exit": {"Q"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newHarness(t, server)
+	h.keys("q")
+	if h.quit {
+		t.Error("expected q not to exit once rebound")
+	}
+	h.keys("/", "Q")
+	if h.quit {
+		t.Error("expected the exit key to be typed in the filter")
+	}
+	h.keys("esc", "Q")
+	if !h.quit {
+		t.Error("expected rebound key to exit")
+	}
+}
+
+func TestKeyConfigErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		keys     map[string]map[string][]string
+		expected string
+	}{
+		"unknown keymap":  {map[string]map[string][]string{"filter": {}}, `unknown keymap "filter"`},
+		"unknown binding": {map[string]map[string][]string{keymap.Viewport: {"StdErr": {"E"}}}, "viewport.StdErr"},
+		"conflict": {
+			map[string]map[string][]string{keymap.Global: {"StdErr": {"d"}}},
+			`"d" is bound to global.StdErr and viewport.HalfPageDown`,
+		},
+		"exit conflicts everywhere": {
+			map[string]map[string][]string{keymap.Prompt: {"Yes": {"ctrl+c"}}},
+			`"ctrl+c" is bound to global.Exit and prompt.Yes`,
+		},
+	} {
+		err := withKeys(t, test.keys)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error containing %s, got %v", name, test.expected, err)
+		}
+	}
+
+	// dialog bindings are only active in dialogs, so may share keys with page bindings
+	if err := withKeys(t, map[string]map[string][]string{keymap.Viewport: {"ConfirmSave": {"s"}}}); err != nil {
+		t.Error(err)
+	}
+}
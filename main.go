@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"os"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"wander/clipboard"
 	"wander/components/header"
 	"wander/components/page"
+	"wander/components/prompt"
 	"wander/components/toast"
 	"wander/components/viewport"
+	"wander/config"
 	"wander/constants"
 	"wander/dev"
+	"wander/formatter"
 	"wander/keymap"
 	"wander/message"
 	"wander/nomad"
@@ -20,8 +30,9 @@ import (
 )
 
 type model struct {
-	nomadUrl        string
-	nomadToken      string
+	client          *nomad.Client
+	pageCtx         context.Context
+	cancelPageCtx   context.CancelFunc
 	header          header.Model
 	currentPage     nomad.Page
 	jobsPage        page.Model
@@ -30,19 +41,80 @@ type model struct {
 	allocSpecPage   page.Model
 	logsPage        page.Model
 	loglinePage     page.Model
+	allocFilesPage  page.Model
+	allocFilePage   page.Model
+	templatesPage   page.Model
+	statsPage       page.Model
+	nodesPage       page.Model
+	nodePage        page.Model
+	servicesPage    page.Model
+	servicePage     page.Model
+	volumesPage     page.Model
+	volumePage      page.Model
+	pluginsPage     page.Model
+	pluginPage      page.Model
+	tokenPage       page.Model
+	overviewPage    page.Model
+	taskGroupsPage  page.Model
+	scalePage       page.Model
+	regionsPage     page.Model
+	helpPage        page.Model
+	showHelp        bool
+	jobs            nomad.Jobs
+	expandedJobs    map[string]bool
 	jobID           string
+	scaleTaskGroups map[string]nomad.ScalableTaskGroup
 	allocID         string
 	taskName        string
 	logline         string
+	filesPath       string
+	filePath        string
 	logType         nomad.LogType
+	logs            nomad.Logs
+	logTailBytes    int
+	loadingEarlier  bool
+	logsGeneration  int
+	statsHistory    nomad.StatsHistory
+	statsPollID     int
+	nodeID          string
+	nodeDrain       bool
+	nodeEligible    bool
+	serviceName     string
+	namespace       string
+	region          string
+	datacenter      string
+	volumeID        string
+	pluginID        string
+	permissions     nomad.Permissions
 	width, height   int
 	initialized     bool
 	toastMessage    string
 	showToast       bool
+	prompt          prompt.Model
+	promptAction    func(m *model, value string) tea.Cmd
 	err             error
+	// copyToClipboard is replaced in tests, which don't have a terminal or clipboard
+	copyToClipboard func(text string) error
 }
 
 func initialModel() model {
+	client, logTailBytes := clientFromEnv(), logTailBytesFromEnv()
+
+	firstPage := nomad.JobsPage
+	switch startPage := os.Getenv(constants.StartPageEnvVariable); startPage {
+	case "", "jobs":
+	case "overview":
+		firstPage = nomad.OverviewPage
+	default:
+		fmt.Printf("Environment variable %s must be one of: jobs, overview\n", constants.StartPageEnvVariable)
+		os.Exit(1)
+	}
+
+	return newModel(client, firstPage, logTailBytes)
+}
+
+// clientFromEnv creates a client for the Nomad agent and region set in the environment, exiting if they're unset
+func clientFromEnv() *nomad.Client {
 	nomadToken := os.Getenv(constants.NomadTokenEnvVariable)
 	if nomadToken == "" {
 		fmt.Printf("Set environment variable %s\n", constants.NomadTokenEnvVariable)
@@ -55,14 +127,62 @@ func initialModel() model {
 		os.Exit(1)
 	}
 
-	firstPage := nomad.JobsPage
-	initialHeader := header.New(constants.LogoString, nomadUrl, "")
+	client := nomad.NewClient(nomadUrl, nomadToken)
+	client.SetRegion(os.Getenv(constants.NomadRegionEnvVariable))
+	return client
+}
+
+func logTailBytesFromEnv() int {
+	logTailBytes := constants.DefaultLogTailBytes
+	if logTailBytesString := os.Getenv(constants.LogTailBytesEnvVariable); logTailBytesString != "" {
+		parsed, err := strconv.Atoi(logTailBytesString)
+		if err != nil || parsed <= 0 {
+			fmt.Printf("Environment variable %s must be a positive integer\n", constants.LogTailBytesEnvVariable)
+			os.Exit(1)
+		}
+		logTailBytes = parsed
+	}
+	return logTailBytes
+}
+
+// loadConfig loads the config file and applies its key bindings, exiting if it's invalid
+func loadConfig() config.Config {
+	cfg, err := config.Load(config.Path())
+	if err == nil {
+		err = applyKeyConfig(cfg.Keys)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// applyTheme sets the styles to the configured theme, exiting if it's invalid
+func applyTheme(cfg config.Config) {
+	noColor := os.Getenv(constants.NoColorEnvVariable) != "" || lipgloss.ColorProfile() == termenv.Ascii
+	if err := applyThemeConfig(cfg, noColor, lipgloss.HasDarkBackground); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newModel creates a model that starts on firstPage once it receives the window size
+func newModel(client *nomad.Client, firstPage nomad.Page, logTailBytes int) model {
+	initialHeader := header.New(constants.LogoString, client.URL(), "")
+	initialHeader.Region = client.Region()
+	pageCtx, cancelPageCtx := context.WithCancel(context.Background())
 
 	return model{
-		nomadUrl:    nomadUrl,
-		nomadToken:  nomadToken,
-		header:      initialHeader,
-		currentPage: firstPage,
+		client:          client,
+		pageCtx:         pageCtx,
+		cancelPageCtx:   cancelPageCtx,
+		header:          initialHeader,
+		currentPage:     firstPage,
+		logTailBytes:    logTailBytes,
+		expandedJobs:    make(map[string]bool),
+		region:          client.Region(),
+		copyToClipboard: clipboard.Copy,
 	}
 }
 
@@ -79,27 +199,77 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// always exit if desired, or don't respond if editing filter or saving
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+
+		// always exit if desired, or don't respond if typing in the filter or a dialog
 		if key.Matches(msg, keymap.KeyMap.Exit) {
-			addingQToFilter := m.currentPageFilterFocused()
-			saving := m.currentPageViewportSaving()
-			typingQWhileFilteringOrSaving := (addingQToFilter || saving) && msg.String() == "q"
-			if !typingQWhileFilteringOrSaving {
+			typingExitKey := (m.currentPageTypingInput() || m.prompt.Active()) && msg.Type == tea.KeyRunes
+			if !typingExitKey {
 				return m, tea.Quit
 			}
 		}
 
-		if !m.currentPageFilterFocused() && !m.currentPageViewportSaving() {
+		if m.prompt.Active() {
+			m.prompt, cmd = m.prompt.Update(msg)
+			return m, cmd
+		}
+
+		if !m.currentPageTypingInput() {
 			switch {
+			case key.Matches(msg, keymap.KeyMap.Help):
+				m.openHelp()
+				return m, nil
+
 			case key.Matches(msg, keymap.KeyMap.Forward):
 				if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
 					switch m.currentPage {
 					case nomad.JobsPage:
-						m.jobID = nomad.JobIDFromKey(selectedPageRow.Key)
+						m.jobID, m.namespace = nomad.JobIDAndNamespaceFromKey(selectedPageRow.Key)
 					case nomad.AllocationsPage:
 						m.allocID, m.taskName = nomad.AllocIDAndTaskNameFromKey(selectedPageRow.Key)
 					case nomad.LogsPage:
 						m.logline = selectedPageRow.Row
+					case nomad.OverviewPage:
+						if linkPage, jobID, namespace, filter, isLink := nomad.OverviewLinkFromKey(selectedPageRow.Key); isLink {
+							m.jobID, m.namespace = jobID, namespace
+							m.setPage(linkPage)
+							m.getCurrentPageModel().SetFilter(filter)
+							return m, m.getCurrentPageCmd()
+						}
+					case nomad.RegionsPage:
+						if region := nomad.RegionFromKey(selectedPageRow.Key); region != m.region {
+							// datacenters and jobs differ between regions
+							m.datacenter = ""
+							m.expandedJobs = make(map[string]bool)
+							m.region = region
+						}
+						m.client.SetRegion(m.region)
+						m.header.Region = m.region
+						m.setPageWindowSize()
+						m.setPage(nomad.JobsPage)
+						return m, tea.Batch(m.getCurrentPageCmd(), nomad.FetchPermissions(context.Background(), m.client))
+					case nomad.NodesPage:
+						m.nodeID = nomad.NodeIDFromKey(selectedPageRow.Key)
+					case nomad.ServicesPage:
+						m.serviceName, m.namespace = nomad.ServiceNameAndNamespaceFromKey(selectedPageRow.Key)
+					case nomad.VolumesPage:
+						m.volumeID, m.namespace, m.pluginID = nomad.VolumeIDNamespaceAndPluginIDFromKey(selectedPageRow.Key)
+					case nomad.PluginsPage:
+						m.pluginID = nomad.PluginIDFromKey(selectedPageRow.Key)
+					case nomad.ServicePage:
+						// the task registering the service must be found before its logs can be shown
+						m.allocID, m.jobID = nomad.AllocIDAndJobIDFromKey(selectedPageRow.Key)
+						return m, nomad.FetchServiceTask(m.pageCtx, m.client, m.allocID, m.serviceName)
+					case nomad.AllocFilesPage:
+						filePath, isDir := nomad.FilePathAndIsDirFromKey(selectedPageRow.Key)
+						if isDir {
+							m.filesPath = filePath
+							m.setPage(nomad.AllocFilesPage)
+							return m, m.getCurrentPageCmd()
+						}
+						m.filePath = filePath
 					}
 
 					nextPage := m.currentPage.Forward()
@@ -110,7 +280,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			case key.Matches(msg, keymap.KeyMap.Back):
-				if !m.currentPageFilterApplied() {
+				// back clears marks, then the filter, before leaving the page
+				if !m.currentPageFilterApplied() && !m.getCurrentPageModel().HasMarks() {
+					if m.currentPage == nomad.AllocFilesPage && m.filesPath != "/" {
+						m.filesPath = path.Dir(m.filesPath)
+						m.setPage(nomad.AllocFilesPage)
+						return m, m.getCurrentPageCmd()
+					}
+
 					prevPage := m.currentPage.Backward()
 					if prevPage != m.currentPage {
 						m.setPage(prevPage)
@@ -125,11 +302,90 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			switch {
+			case key.Matches(msg, keymap.KeyMap.CopyID):
+				if m.currentPage == nomad.LoglinePage {
+					return m, m.copy(strings.TrimSpace(m.logline), "log line")
+				}
+				if copiedName := m.currentPage.CopiedName(); copiedName != "" {
+					var values []string
+					copied := make(map[string]bool)
+					for _, row := range m.actionRows() {
+						// e.g. rows for each task of an allocation have the same alloc id
+						if value := m.currentPage.CopiedValue(row); !copied[value] {
+							copied[value] = true
+							values = append(values, value)
+						}
+					}
+					if len(values) > 1 {
+						copiedName = fmt.Sprintf("%d %ss", len(values), copiedName)
+					}
+					return m, m.copy(strings.Join(values, "\n"), copiedName)
+				}
+
+			case key.Matches(msg, keymap.KeyMap.CopyRow):
+				var rows []string
+				for _, row := range m.actionRows() {
+					rows = append(rows, strings.TrimSpace(row.Row))
+				}
+				copiedName := "row"
+				if len(rows) > 1 {
+					copiedName = fmt.Sprintf("%d rows", len(rows))
+				}
+				return m, m.copy(strings.Join(rows, "\n"), copiedName)
+
+			case key.Matches(msg, keymap.KeyMap.CopyView):
+				return m, m.copy(m.getCurrentPageModel().FilteredText(), "view")
+			}
+
+			if m.currentPage == nomad.JobsPage && key.Matches(msg, keymap.KeyMap.Stop) {
+				permitted, deniedNamespaces := m.permittedRows(m.actionRows(), nomad.CapabilitySubmitJob, nomad.JobIDAndNamespaceFromKey)
+				if len(permitted) == 0 && len(deniedNamespaces) > 0 {
+					return m, m.setToast("", describeDenied(nomad.CapabilitySubmitJob, deniedNamespaces))
+				}
+				var jobKeys, jobIDs []string
+				for _, row := range permitted {
+					jobID, _ := nomad.JobIDAndNamespaceFromKey(row.Key)
+					jobKeys, jobIDs = append(jobKeys, row.Key), append(jobIDs, jobID)
+				}
+				if len(jobKeys) > 0 {
+					question := fmt.Sprintf("Stop %s?%s", describeTargets("job", jobIDs), describeSkipped(nomad.CapabilitySubmitJob, deniedNamespaces))
+					m.confirm(question, func(m *model, _ string) tea.Cmd {
+						m.jobsPage.ClearMarks()
+						return nomad.StopJobs(context.Background(), m.client, jobKeys)
+					})
+				}
+				return m, nil
+			}
+
+			if m.currentPage == nomad.AllocationsPage && key.Matches(msg, keymap.KeyMap.Restart) {
+				permitted, deniedNamespaces := m.permittedRows(m.actionRows(), nomad.CapabilityAllocLifecycle, nomad.AllocIDAndNamespaceFromKey)
+				if len(permitted) == 0 && len(deniedNamespaces) > 0 {
+					return m, m.setToast("", describeDenied(nomad.CapabilityAllocLifecycle, deniedNamespaces))
+				}
+				var allocIDs, shortAllocIDs []string
+				restarting := make(map[string]bool)
+				for _, row := range permitted {
+					if allocID, _ := nomad.AllocIDAndTaskNameFromKey(row.Key); !restarting[allocID] {
+						restarting[allocID] = true
+						allocIDs, shortAllocIDs = append(allocIDs, allocID), append(shortAllocIDs, formatter.ShortAllocID(allocID))
+					}
+				}
+				if len(allocIDs) > 0 {
+					question := fmt.Sprintf("Restart %s?%s", describeTargets("allocation", shortAllocIDs), describeSkipped(nomad.CapabilityAllocLifecycle, deniedNamespaces))
+					m.confirm(question, func(m *model, _ string) tea.Cmd {
+						m.allocationsPage.ClearMarks()
+						return nomad.RestartAllocations(context.Background(), m.client, allocIDs)
+					})
+				}
+				return m, nil
+			}
+
 			if key.Matches(msg, keymap.KeyMap.Spec) {
 				if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
 					switch m.currentPage {
 					case nomad.JobsPage:
-						m.jobID = nomad.JobIDFromKey(selectedPageRow.Key)
+						m.jobID, m.namespace = nomad.JobIDAndNamespaceFromKey(selectedPageRow.Key)
 						m.setPage(nomad.JobSpecPage)
 						return m, m.getCurrentPageCmd()
 					case nomad.AllocationsPage:
@@ -140,12 +396,222 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			if m.currentPage == nomad.AllocationsPage {
+				if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+					switch {
+					case key.Matches(msg, keymap.KeyMap.Files):
+						m.allocID, m.taskName = nomad.AllocIDAndTaskNameFromKey(selectedPageRow.Key)
+						m.filesPath = "/"
+						m.setPage(nomad.AllocFilesPage)
+						return m, m.getCurrentPageCmd()
+
+					case key.Matches(msg, keymap.KeyMap.Templates):
+						m.allocID, m.taskName = nomad.AllocIDAndTaskNameFromKey(selectedPageRow.Key)
+						m.setPage(nomad.TemplatesPage)
+						return m, m.getCurrentPageCmd()
+
+					case key.Matches(msg, keymap.KeyMap.Stats):
+						m.allocID, m.taskName = nomad.AllocIDAndTaskNameFromKey(selectedPageRow.Key)
+						m.statsHistory = make(nomad.StatsHistory)
+						m.statsPollID++
+						m.setPage(nomad.StatsPage)
+						return m, tea.Batch(m.getCurrentPageCmd(), nomad.GetStatsTickCmd(m.statsPollID))
+					}
+				}
+			}
+
+			if m.currentPage == nomad.JobsPage {
+				switch {
+				case key.Matches(msg, keymap.KeyMap.Nodes):
+					m.setPage(nomad.NodesPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Services):
+					m.setPage(nomad.ServicesPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Volumes):
+					m.setPage(nomad.VolumesPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Token):
+					m.setPage(nomad.TokenPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Overview):
+					m.setPage(nomad.OverviewPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Expand):
+					if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+						group := nomad.JobGroupFromKey(selectedPageRow.Key)
+						m.expandedJobs[group] = !m.expandedJobs[group]
+						_, allPageData, rowStyles := nomad.JobsAsTable(m.jobs, m.expandedJobs, m.datacenter)
+						m.jobsPage.SetRowStyles(rowStyles)
+						m.jobsPage.SetAllPageData(allPageData)
+						m.jobsPage.SetViewportCursorToKey(nomad.JobKeyFromGroup(group))
+					}
+					return m, nil
+
+				case key.Matches(msg, keymap.KeyMap.TaskGroups):
+					if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+						m.jobID, m.namespace = nomad.JobIDAndNamespaceFromKey(selectedPageRow.Key)
+						m.setPage(nomad.TaskGroupsPage)
+						return m, m.getCurrentPageCmd()
+					}
+
+				case key.Matches(msg, keymap.KeyMap.Scale):
+					if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+						m.jobID, m.namespace = nomad.JobIDAndNamespaceFromKey(selectedPageRow.Key)
+						m.setPage(nomad.ScalePage)
+						return m, m.getCurrentPageCmd()
+					}
+
+				case key.Matches(msg, keymap.KeyMap.Region):
+					m.setPage(nomad.RegionsPage)
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Dispatch):
+					if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+						jobID, namespace := nomad.JobIDAndNamespaceFromKey(selectedPageRow.Key)
+						if !m.permissions.HasNamespaceCapability(namespace, nomad.CapabilityDispatchJob) {
+							return m, m.setToast("", fmt.Sprintf("token lacks %s in namespace %s", nomad.CapabilityDispatchJob, namespace))
+						}
+						return m, nomad.FetchDispatchSpec(context.Background(), m.client, jobID, namespace)
+					}
+				}
+			}
+
+			if m.currentPage == nomad.VolumesPage || m.currentPage == nomad.VolumePage {
+				switch {
+				case key.Matches(msg, keymap.KeyMap.Plugins):
+					if m.currentPage == nomad.VolumePage {
+						m.setPage(nomad.PluginPage)
+					} else {
+						m.setPage(nomad.PluginsPage)
+					}
+					return m, m.getCurrentPageCmd()
+
+				case key.Matches(msg, keymap.KeyMap.Detach) && m.currentPage == nomad.VolumePage:
+					if !m.permissions.HasNamespaceCapability(m.namespace, nomad.CapabilityCSIWriteVolume) {
+						return m, m.setToast("", fmt.Sprintf("token lacks %s in namespace %s", nomad.CapabilityCSIWriteVolume, m.namespace))
+					}
+					if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+						if nodeID, allocID, isClaim := nomad.NodeIDAndAllocIDFromVolumeClaimKey(selectedPageRow.Key); isClaim {
+							volumeID, namespace := m.volumeID, m.namespace
+							question := fmt.Sprintf(
+								"Detach volume %s from node %s, releasing the claim of %s?",
+								volumeID, formatter.ShortAllocID(nodeID), formatter.ShortAllocID(allocID),
+							)
+							m.confirm(question, func(m *model, _ string) tea.Cmd {
+								return nomad.DetachVolume(context.Background(), m.client, volumeID, namespace, nodeID)
+							})
+						}
+					}
+					return m, nil
+				}
+			}
+
+			if (m.currentPage == nomad.JobsPage || m.currentPage == nomad.AllocationsPage) && key.Matches(msg, keymap.KeyMap.Datacenter) {
+				placeholder := "all datacenters"
+				if datacenters := m.jobs.Datacenters(); len(datacenters) > 0 {
+					placeholder = strings.Join(datacenters, ", ")
+				}
+				return m, m.ask("Datacenter (empty for all):", placeholder, m.datacenter, func(m *model, value string) tea.Cmd {
+					m.datacenter = strings.TrimSpace(value)
+					m.getCurrentPageModel().SetFilterPrefix(m.getFilterPrefix(m.currentPage))
+					if m.currentPage == nomad.JobsPage {
+						_, allPageData, rowStyles := nomad.JobsAsTable(m.jobs, m.expandedJobs, m.datacenter)
+						m.jobsPage.SetRowStyles(rowStyles)
+						m.jobsPage.SetAllPageData(allPageData)
+						return nil
+					}
+					m.getCurrentPageModel().SetLoading(true)
+					return m.getCurrentPageCmd()
+				})
+			}
+
+			if m.currentPage == nomad.ScalePage && key.Matches(msg, keymap.KeyMap.Scale) {
+				if !m.permissions.HasNamespaceCapability(m.namespace, nomad.CapabilityScaleJob) {
+					return m, m.setToast("", fmt.Sprintf("token lacks %s in namespace %s", nomad.CapabilityScaleJob, m.namespace))
+				}
+				if selectedPageRow, err := m.getCurrentPageModel().GetSelectedPageRow(); err == nil {
+					if taskGroupName, isTaskGroup := nomad.TaskGroupFromScaleKey(selectedPageRow.Key); isTaskGroup {
+						jobID, namespace, taskGroup := m.jobID, m.namespace, m.scaleTaskGroups[taskGroupName]
+						question := fmt.Sprintf("New count for group %s:", taskGroupName)
+						if bounds := taskGroup.Bounds(); bounds != "" {
+							question = fmt.Sprintf("New count for group %s (%s):", taskGroupName, bounds)
+						}
+						return m, m.ask(question, "", strconv.Itoa(taskGroup.Desired), func(m *model, value string) tea.Cmd {
+							count, err := taskGroup.ParseCount(value)
+							if err != nil {
+								return actionErrorCmd(err)
+							}
+							return m.ask("Message (optional):", "reason for scaling", "", func(m *model, scaleMessage string) tea.Cmd {
+								question := fmt.Sprintf("Scale %s group %s from %d to %d?", jobID, taskGroupName, taskGroup.Desired, count)
+								m.confirm(question, func(m *model, _ string) tea.Cmd {
+									return nomad.ScaleTaskGroup(context.Background(), m.client, jobID, namespace, taskGroupName, count, scaleMessage)
+								})
+								return nil
+							})
+						})
+					}
+				}
+				return m, nil
+			}
+
+			if m.currentPage == nomad.NodePage {
+				nodeID, shortNodeID := m.nodeID, formatter.ShortAllocID(m.nodeID)
+				if key.Matches(msg, keymap.KeyMap.Drain, keymap.KeyMap.Eligibility) && !m.permissions.CanWriteNodes() {
+					return m, m.setToast("", "token lacks node write permission")
+				}
+				switch {
+				case key.Matches(msg, keymap.KeyMap.Drain):
+					if m.nodeDrain {
+						m.confirm(fmt.Sprintf("Disable drain on node %s and mark it eligible?", shortNodeID), func(m *model, _ string) tea.Cmd {
+							return nomad.SetNodeDrain(context.Background(), m.client, nodeID, false, 0)
+						})
+						return m, nil
+					}
+					question := fmt.Sprintf("Drain deadline for node %s (e.g. 1h, none, force):", shortNodeID)
+					return m, m.ask(question, "", "1h", func(m *model, value string) tea.Cmd {
+						deadline, err := nomad.ParseDrainDeadline(value)
+						if err != nil {
+							return actionErrorCmd(err)
+						}
+						m.confirm(fmt.Sprintf("Drain node %s with deadline %s?", shortNodeID, value), func(m *model, _ string) tea.Cmd {
+							return nomad.SetNodeDrain(context.Background(), m.client, nodeID, true, deadline)
+						})
+						return nil
+					})
+
+				case key.Matches(msg, keymap.KeyMap.Eligibility):
+					eligible := !m.nodeEligible
+					question := fmt.Sprintf("Mark node %s ineligible for scheduling?", shortNodeID)
+					if eligible {
+						question = fmt.Sprintf("Mark node %s eligible for scheduling?", shortNodeID)
+					}
+					m.confirm(question, func(m *model, _ string) tea.Cmd {
+						return nomad.SetNodeEligibility(context.Background(), m.client, nodeID, eligible)
+					})
+					return m, nil
+				}
+			}
+
 			if m.currentPage == nomad.LogsPage {
+				viewportKeyMap := viewport.GetKeyMap()
+				scrollingUp := key.Matches(msg, viewportKeyMap.Up, viewportKeyMap.HalfPageUp, viewportKeyMap.PageUp, viewportKeyMap.Top)
+				if scrollingUp && m.logsPage.ViewportCursorAtTop() && !m.logs.ReachedStart && !m.loadingEarlier {
+					m.loadingEarlier = true
+					cmds = append(cmds, nomad.FetchEarlierLogs(m.pageCtx, m.client, m.allocID, m.taskName, m.logs, m.logTailBytes))
+				}
+
 				switch {
 				case key.Matches(msg, keymap.KeyMap.StdOut):
 					if m.logType != nomad.StdOut {
 						m.logType = nomad.StdOut
 						m.getCurrentPageModel().SetViewportStyle(style.ViewportHeaderStyle, style.StdOut)
+						m.getCurrentPageModel().SetRowStyles(nil)
 						m.getCurrentPageModel().SetLoading(true)
 						return m, m.getCurrentPageCmd()
 					}
@@ -155,6 +621,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.logType = nomad.StdErr
 						stdErrHeaderStyle := style.ViewportHeaderStyle.Copy().Inherit(style.StdErr)
 						m.getCurrentPageModel().SetViewportStyle(stdErrHeaderStyle, style.StdErr)
+						m.getCurrentPageModel().SetRowStyles(nil)
+						m.getCurrentPageModel().SetLoading(true)
+						return m, m.getCurrentPageCmd()
+					}
+
+				case key.Matches(msg, keymap.KeyMap.StdOutAndErr):
+					if m.logType != nomad.StdOutAndErr {
+						m.logType = nomad.StdOutAndErr
+						m.getCurrentPageModel().SetViewportStyle(style.ViewportHeaderStyle, style.StdOut)
+						m.getCurrentPageModel().SetRowStyles(map[string]lipgloss.Style{nomad.StdErr.ShortString(): style.StdErr})
 						m.getCurrentPageModel().SetLoading(true)
 						return m, m.getCurrentPageCmd()
 					}
@@ -163,6 +639,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case message.ErrMsg:
+		// requests for a page are cancelled when leaving it, which isn't an error
+		if errors.Is(msg.Err, context.Canceled) {
+			return m, nil
+		}
 		m.err = msg
 		return m, nil
 
@@ -171,35 +651,156 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case viewport.SaveStatusMsg:
-		if msg.Err != "" {
-			m.toastMessage = style.ErrorToast.Width(m.width).Render(fmt.Sprintf("Error: %s", msg.Err))
-		} else {
-			m.toastMessage = style.SuccessToast.Width(m.width).Render(msg.SuccessMessage)
+		return m, m.setToast(msg.SuccessMessage, msg.Err)
+
+	case viewport.JumpErrorMsg:
+		return m, m.setToast("", msg.Err)
+
+	case nomad.EarlierLogsErrorMsg:
+		if msg.Generation != m.logsGeneration {
+			return m, nil
+		}
+		m.loadingEarlier = false
+		if errors.Is(msg.Err, context.Canceled) {
+			return m, nil
+		}
+		return m, m.setToast("", fmt.Sprintf("loading earlier logs: %s", msg.Err))
+
+	case message.CopyStatusMsg:
+		return m, m.setToast(msg.SuccessMessage, msg.Err)
+
+	case message.ActionStatusMsg:
+		cmds = append(cmds, m.setToast(msg.SuccessMessage, msg.Err))
+		if msg.Err == "" && m.currentPage.Loads() {
+			cmds = append(cmds, m.getCurrentPageCmd())
 		}
-		m.showToast = true
-		cmds = append(cmds, toast.GetToastTimeoutCmd())
 		return m, tea.Batch(cmds...)
 
+	case nomad.AllocFileNotTextMsg:
+		if m.currentPage != nomad.AllocFilePage || msg.FilePath != m.filePath {
+			return m, nil
+		}
+		m.setPage(nomad.AllocFilesPage)
+		return m, tea.Batch(m.setToast("", fmt.Sprintf("%s isn't a text file", path.Base(msg.FilePath))), m.getCurrentPageCmd())
+
+	case nomad.DispatchSpecLoadedMsg:
+		return m, m.askDispatch(nomad.NewDispatchForm(msg.Spec))
+
+	case nomad.JobDispatchedMsg:
+		m.jobID, m.namespace = msg.DispatchedJobID, msg.Namespace
+		m.setPage(nomad.AllocationsPage)
+		return m, tea.Batch(
+			m.setToast(fmt.Sprintf("Dispatched %s as %s", msg.JobID, msg.DispatchedJobID), ""),
+			m.getCurrentPageCmd(),
+		)
+
+	case prompt.SubmitMsg:
+		action := m.promptAction
+		m.promptAction = nil
+		if action != nil {
+			return m, action(&m, msg.Value)
+		}
+		return m, nil
+
+	case prompt.CancelMsg:
+		m.promptAction = nil
+		return m, nil
+
+	case nomad.ServiceTaskLoadedMsg:
+		if m.currentPage != nomad.ServicePage || msg.AllocID != m.allocID {
+			return m, nil
+		}
+		m.taskName = msg.TaskName
+		m.setPage(nomad.LogsPage)
+		return m, m.getCurrentPageCmd()
+
+	case nomad.PermissionsLoadedMsg:
+		m.permissions = msg.Permissions
+		m.setKeyHelp()
+		return m, nil
+
+	case nomad.ScaleLoadedMsg:
+		if msg.JobID != m.jobID {
+			return m, nil
+		}
+		m.scaleTaskGroups = msg.TaskGroups
+		m.setPageData(nomad.ScalePage, []string{}, msg.AllPageData)
+
+	case nomad.NodeLoadedMsg:
+		if msg.NodeID != m.nodeID {
+			return m, nil
+		}
+		m.nodeDrain, m.nodeEligible = msg.Drain, msg.Eligible
+		m.setPageData(nomad.NodePage, []string{}, msg.AllPageData)
+
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		if !m.initialized {
 			m.initialize()
-			cmds = append(cmds, m.getCurrentPageCmd())
+			cmds = append(cmds, m.getCurrentPageCmd(), nomad.FetchPermissions(context.Background(), m.client))
 		} else {
 			m.setPageWindowSize()
 		}
+		m.setKeyHelp()
 
 	case nomad.PageLoadedMsg:
-		m.setPage(msg.Page)
-		m.getCurrentPageModel().SetHeader(msg.TableHeader)
-		m.getCurrentPageModel().SetAllPageData(msg.AllPageData)
-		m.getCurrentPageModel().SetLoading(false)
-		m.getCurrentPageModel().SetViewportXOffset(0)
-		if m.currentPage == nomad.LogsPage {
-			m.logsPage.SetViewportCursorToBottom()
+		// a page left before it finished loading isn't shown, nor given another page's row styles
+		if msg.Page != m.currentPage {
+			return m, nil
+		}
+		m.setPageData(msg.Page, msg.TableHeader, msg.AllPageData)
+		if msg.RowStyles != nil {
+			m.getCurrentPageModel().SetRowStyles(msg.RowStyles)
+		}
+
+	case nomad.JobsLoadedMsg:
+		m.jobs = msg.Jobs
+		tableHeader, allPageData, rowStyles := nomad.JobsAsTable(m.jobs, m.expandedJobs, m.datacenter)
+		m.jobsPage.SetRowStyles(rowStyles)
+		m.setPageData(nomad.JobsPage, tableHeader, allPageData)
+
+	case nomad.StatsLoadedMsg:
+		if msg.AllocID != m.allocID {
+			return m, nil
+		}
+		m.statsHistory.Add(msg.Stats)
+		tableHeader, allPageData := nomad.StatsAsTable(m.statsHistory)
+		m.statsPage.SetHeader(tableHeader)
+		m.statsPage.SetAllPageData(allPageData)
+		m.statsPage.SetLoading(false)
+		return m, nil
+
+	case nomad.StatsErrorMsg:
+		if msg.AllocID != m.allocID || m.currentPage != nomad.StatsPage || errors.Is(msg.Err, context.Canceled) {
+			return m, nil
+		}
+		return m, m.setToast("", fmt.Sprintf("polling resource usage: %s", msg.Err))
+
+	case nomad.StatsTickMsg:
+		if m.currentPage == nomad.StatsPage && msg.PollID == m.statsPollID {
+			return m, tea.Batch(m.getCurrentPageCmd(), nomad.GetStatsTickCmd(msg.PollID))
+		}
+		return m, nil
+
+	case nomad.LogsLoadedMsg:
+		if msg.Logs.LogType != m.logType || msg.Generation != m.logsGeneration {
+			return m, nil
+		}
+		m.logs = msg.Logs
+		m.loadingEarlier = false
+		if msg.Earlier {
+			m.logsPage.SetHeader(msg.TableHeader)
+			m.logsPage.PrependPageData(msg.AllPageData, msg.PrependedRows)
+		} else {
+			m.setPageData(nomad.LogsPage, msg.TableHeader, msg.AllPageData)
 		}
 	}
 
+	if m.prompt.Active() {
+		m.prompt, cmd = m.prompt.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	currentPageModel := m.getCurrentPageModel()
 	*currentPageModel, cmd = currentPageModel.Update(msg)
 	cmds = append(cmds, cmd)
@@ -215,16 +816,165 @@ func (m model) View() string {
 	}
 
 	pageView := m.header.View() + "\n" + m.getCurrentPageModel().View()
+	if m.showHelp {
+		pageView = m.helpPage.View()
+	}
 
 	if m.showToast {
-		lines := strings.Split(pageView, "\n")
-		lines = lines[:len(lines)-lipgloss.Height(m.toastMessage)]
-		pageView = strings.Join(lines, "\n") + "\n" + m.toastMessage
+		pageView = replaceBottomLines(pageView, m.toastMessage)
+	}
+
+	if m.prompt.Active() {
+		pageView = replaceBottomLines(pageView, m.prompt.View())
 	}
 
 	return pageView
 }
 
+// openHelp shows every key binding available on the current page, full screen
+func (m *model) openHelp() {
+	m.helpPage.SetHeader([]string{fmt.Sprintf("Keys on the %s page", m.currentPage)})
+	m.helpPage.SetFilter("")
+	m.helpPage.SetAllPageData(nomad.KeyHelpAsRows(nomad.GetPageKeyHelpSections(m.currentPage, m.permissions)))
+	m.helpPage.SetLoading(false)
+	m.showHelp = true
+}
+
+// updateHelp handles keys while help is shown. The help key closes it, as does back once the filter is cleared.
+func (m model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	typing := m.helpPage.TypingInput()
+	switch {
+	case key.Matches(msg, keymap.KeyMap.Exit) && !(typing && msg.Type == tea.KeyRunes):
+		return m, tea.Quit
+
+	case !typing && (key.Matches(msg, keymap.KeyMap.Help) || key.Matches(msg, keymap.KeyMap.Back) && !m.helpPage.FilterApplied()):
+		m.showHelp = false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.helpPage, cmd = m.helpPage.Update(msg)
+	return m, cmd
+}
+
+func replaceBottomLines(view, bottom string) string {
+	lines := strings.Split(view, "\n")
+	lines = lines[:max(0, len(lines)-lipgloss.Height(bottom))]
+	return strings.Join(lines, "\n") + "\n" + bottom
+}
+
+func (m *model) setToast(successMessage, err string) tea.Cmd {
+	if err != "" {
+		m.toastMessage = style.ErrorToast.Width(m.width).Render(fmt.Sprintf("Error: %s", err))
+	} else {
+		m.toastMessage = style.SuccessToast.Width(m.width).Render(successMessage)
+	}
+	m.showToast = true
+	return toast.GetToastTimeoutCmd()
+}
+
+// copy puts text on the clipboard, confirming what was copied with a toast
+func (m model) copy(text, name string) tea.Cmd {
+	copyToClipboard := m.copyToClipboard
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return message.CopyStatusMsg{Err: err.Error()}
+		}
+		successMessage := fmt.Sprintf("Copied %s to clipboard", name)
+		if !strings.Contains(text, "\n") && len(text) <= 80 {
+			successMessage += ": " + text
+		}
+		return message.CopyStatusMsg{SuccessMessage: successMessage}
+	}
+}
+
+// actionRows returns the marked rows of the current page to copy or act on, or the selected row if none are marked
+func (m model) actionRows() []page.Row {
+	currentPageModel := m.getCurrentPageModel()
+	if marked := currentPageModel.MarkedPageRows(); len(marked) > 0 {
+		return marked
+	}
+	if selectedPageRow, err := currentPageModel.GetSelectedPageRow(); err == nil {
+		return []page.Row{selectedPageRow}
+	}
+	return nil
+}
+
+// describeTargets describes what an action will be taken on for its confirmation, e.g. "job web" or "2 jobs: web, api"
+func describeTargets(noun string, names []string) string {
+	if len(names) == 1 {
+		return fmt.Sprintf("%s %s", noun, names[0])
+	}
+	return fmt.Sprintf("%d %ss: %s", len(names), noun, strings.Join(names, ", "))
+}
+
+// permittedRows splits rows into those in namespaces where the token has capability, and the sorted namespaces of
+// the others. fromKey returns the ID and namespace of a row from its key.
+func (m model) permittedRows(rows []page.Row, capability string, fromKey func(string) (string, string)) ([]page.Row, []string) {
+	var permitted []page.Row
+	denied := make(map[string]bool)
+	for _, row := range rows {
+		if _, namespace := fromKey(row.Key); m.permissions.HasNamespaceCapability(namespace, capability) {
+			permitted = append(permitted, row)
+		} else if namespace == "" {
+			denied["default"] = true
+		} else {
+			denied[namespace] = true
+		}
+	}
+	var deniedNamespaces []string
+	for namespace := range denied {
+		deniedNamespaces = append(deniedNamespaces, namespace)
+	}
+	sort.Strings(deniedNamespaces)
+	return permitted, deniedNamespaces
+}
+
+// describeDenied explains that an action can't be taken in any of namespaces
+func describeDenied(capability string, namespaces []string) string {
+	return fmt.Sprintf("token lacks %s in namespace %s", capability, strings.Join(namespaces, ", "))
+}
+
+// describeSkipped notes the namespaces an action will skip in its confirmation, if any
+func describeSkipped(capability string, namespaces []string) string {
+	if len(namespaces) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Skipping rows in namespace %s, where the token lacks %s.", strings.Join(namespaces, ", "), capability)
+}
+
+// confirm asks the user a yes/no question, running action if they answer yes
+func (m *model) confirm(question string, action func(m *model, value string) tea.Cmd) {
+	m.prompt.Confirm(question)
+	m.promptAction = action
+}
+
+// ask prompts the user for text input, running action with their answer once submitted
+func (m *model) ask(question, placeholder, initialValue string, action func(m *model, value string) tea.Cmd) tea.Cmd {
+	m.promptAction = action
+	return m.prompt.Ask(question, placeholder, initialValue)
+}
+
+// askDispatch prompts for each remaining field of a dispatch form, then confirms and dispatches the job
+func (m *model) askDispatch(form nomad.DispatchForm) tea.Cmd {
+	if form.Done() {
+		m.confirm(fmt.Sprintf("Dispatch %s?", form.Summary()), func(m *model, _ string) tea.Cmd {
+			return nomad.DispatchJob(context.Background(), m.client, form)
+		})
+		return nil
+	}
+	return m.ask(form.Question(), "", "", func(m *model, value string) tea.Cmd {
+		if err := form.Set(value); err != nil {
+			return actionErrorCmd(err)
+		}
+		return m.askDispatch(form)
+	})
+}
+
+func actionErrorCmd(err error) tea.Cmd {
+	return func() tea.Msg { return message.ActionStatusMsg{Err: err.Error()} }
+}
+
 func (m *model) initialize() {
 	pageHeight := m.getPageHeight()
 	m.jobsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.JobsPage), nomad.JobsPage.LoadingString(), true, false)
@@ -233,6 +983,35 @@ func (m *model) initialize() {
 	m.allocSpecPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.AllocSpecPage), nomad.AllocSpecPage.LoadingString(), false, true)
 	m.logsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.LogsPage), nomad.LogsPage.LoadingString(), true, false)
 	m.loglinePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.LoglinePage), nomad.LoglinePage.LoadingString(), false, true)
+	m.allocFilesPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.AllocFilesPage), nomad.AllocFilesPage.LoadingString(), true, false)
+	m.allocFilePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.AllocFilePage), nomad.AllocFilePage.LoadingString(), false, true)
+	m.allocFilePage.SetRowStyles(map[string]lipgloss.Style{nomad.TruncatedKey: style.Warning})
+	m.templatesPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.TemplatesPage), nomad.TemplatesPage.LoadingString(), false, true)
+	m.templatesPage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.statsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.StatsPage), nomad.StatsPage.LoadingString(), false, false)
+	m.statsPage.SetRowStyles(map[string]lipgloss.Style{nomad.StatsHighMemoryKey: style.Warning})
+	m.nodesPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.NodesPage), nomad.NodesPage.LoadingString(), true, false)
+	m.nodePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.NodePage), nomad.NodePage.LoadingString(), false, false)
+	m.nodePage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.servicesPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.ServicesPage), nomad.ServicesPage.LoadingString(), true, false)
+	m.servicePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.ServicePage), nomad.ServicePage.LoadingString(), true, false)
+	m.volumesPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.VolumesPage), nomad.VolumesPage.LoadingString(), true, false)
+	m.volumePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.VolumePage), nomad.VolumePage.LoadingString(), true, false)
+	m.volumePage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.pluginsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.PluginsPage), nomad.PluginsPage.LoadingString(), true, false)
+	m.pluginPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.PluginPage), nomad.PluginPage.LoadingString(), false, false)
+	m.pluginPage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.tokenPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.TokenPage), nomad.TokenPage.LoadingString(), false, false)
+	m.tokenPage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.overviewPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.OverviewPage), nomad.OverviewPage.LoadingString(), true, false)
+	m.overviewPage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.taskGroupsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.TaskGroupsPage), nomad.TaskGroupsPage.LoadingString(), true, false)
+	m.scalePage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.ScalePage), nomad.ScalePage.LoadingString(), true, false)
+	m.scalePage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.regionsPage = page.New(m.width, pageHeight, m.getFilterPrefix(nomad.RegionsPage), nomad.RegionsPage.LoadingString(), true, false)
+	m.helpPage = page.New(m.width, m.height, "Help", "", false, false)
+	m.helpPage.SetRowStyles(map[string]lipgloss.Style{nomad.SectionKey: style.Bold})
+	m.prompt = prompt.New(m.width)
 	m.initialized = true
 }
 
@@ -243,11 +1022,40 @@ func (m *model) setPageWindowSize() {
 	m.allocSpecPage.SetWindowSize(m.width, m.getPageHeight())
 	m.logsPage.SetWindowSize(m.width, m.getPageHeight())
 	m.loglinePage.SetWindowSize(m.width, m.getPageHeight())
+	m.allocFilesPage.SetWindowSize(m.width, m.getPageHeight())
+	m.allocFilePage.SetWindowSize(m.width, m.getPageHeight())
+	m.templatesPage.SetWindowSize(m.width, m.getPageHeight())
+	m.statsPage.SetWindowSize(m.width, m.getPageHeight())
+	m.nodesPage.SetWindowSize(m.width, m.getPageHeight())
+	m.nodePage.SetWindowSize(m.width, m.getPageHeight())
+	m.servicesPage.SetWindowSize(m.width, m.getPageHeight())
+	m.servicePage.SetWindowSize(m.width, m.getPageHeight())
+	m.volumesPage.SetWindowSize(m.width, m.getPageHeight())
+	m.volumePage.SetWindowSize(m.width, m.getPageHeight())
+	m.pluginsPage.SetWindowSize(m.width, m.getPageHeight())
+	m.pluginPage.SetWindowSize(m.width, m.getPageHeight())
+	m.tokenPage.SetWindowSize(m.width, m.getPageHeight())
+	m.overviewPage.SetWindowSize(m.width, m.getPageHeight())
+	m.taskGroupsPage.SetWindowSize(m.width, m.getPageHeight())
+	m.scalePage.SetWindowSize(m.width, m.getPageHeight())
+	m.regionsPage.SetWindowSize(m.width, m.getPageHeight())
+	m.helpPage.SetWindowSize(m.width, m.height)
+	m.prompt.SetWidth(m.width)
+}
+
+// setKeyHelp shows the current page's key help in the header, cut to fit its width
+func (m *model) setKeyHelp() {
+	m.header.KeyHelp = nomad.GetPageKeyHelp(m.currentPage, m.permissions, m.header.KeyHelpWidth(m.width))
 }
 
 func (m *model) setPage(page nomad.Page) {
+	if page != m.currentPage {
+		// cancel requests still in flight for the page being left
+		m.cancelPageCtx()
+		m.pageCtx, m.cancelPageCtx = context.WithCancel(context.Background())
+	}
 	m.currentPage = page
-	m.header.KeyHelp = nomad.GetPageKeyHelp(page)
+	m.setKeyHelp()
 	m.getCurrentPageModel().SetFilterPrefix(m.getFilterPrefix(page))
 	if page.Loads() {
 		m.getCurrentPageModel().SetLoading(true)
@@ -256,6 +1064,17 @@ func (m *model) setPage(page nomad.Page) {
 	}
 }
 
+func (m *model) setPageData(page nomad.Page, tableHeader []string, allPageData []page.Row) {
+	m.setPage(page)
+	m.getCurrentPageModel().SetHeader(tableHeader)
+	m.getCurrentPageModel().SetAllPageData(allPageData)
+	m.getCurrentPageModel().SetLoading(false)
+	m.getCurrentPageModel().SetViewportXOffset(0)
+	if m.currentPage == nomad.LogsPage {
+		m.logsPage.SetViewportCursorToBottom()
+	}
+}
+
 func (m *model) getCurrentPageModel() *page.Model {
 	switch m.currentPage {
 	case nomad.JobsPage:
@@ -270,6 +1089,40 @@ func (m *model) getCurrentPageModel() *page.Model {
 		return &m.logsPage
 	case nomad.LoglinePage:
 		return &m.loglinePage
+	case nomad.AllocFilesPage:
+		return &m.allocFilesPage
+	case nomad.AllocFilePage:
+		return &m.allocFilePage
+	case nomad.TemplatesPage:
+		return &m.templatesPage
+	case nomad.StatsPage:
+		return &m.statsPage
+	case nomad.NodesPage:
+		return &m.nodesPage
+	case nomad.NodePage:
+		return &m.nodePage
+	case nomad.ServicesPage:
+		return &m.servicesPage
+	case nomad.ServicePage:
+		return &m.servicePage
+	case nomad.VolumesPage:
+		return &m.volumesPage
+	case nomad.VolumePage:
+		return &m.volumePage
+	case nomad.PluginsPage:
+		return &m.pluginsPage
+	case nomad.PluginPage:
+		return &m.pluginPage
+	case nomad.TokenPage:
+		return &m.tokenPage
+	case nomad.OverviewPage:
+		return &m.overviewPage
+	case nomad.TaskGroupsPage:
+		return &m.taskGroupsPage
+	case nomad.ScalePage:
+		return &m.scalePage
+	case nomad.RegionsPage:
+		return &m.regionsPage
 	default:
 		panic("current page model not found")
 	}
@@ -278,17 +1131,54 @@ func (m *model) getCurrentPageModel() *page.Model {
 func (m *model) getCurrentPageCmd() tea.Cmd {
 	switch m.currentPage {
 	case nomad.JobsPage:
-		return nomad.FetchJobs(m.nomadUrl, m.nomadToken)
+		return nomad.FetchJobs(m.pageCtx, m.client)
 	case nomad.JobSpecPage:
-		return nomad.FetchJobSpec(m.nomadUrl, m.nomadToken, m.jobID)
+		return nomad.FetchJobSpec(m.pageCtx, m.client, m.jobID, m.namespace)
 	case nomad.AllocationsPage:
-		return nomad.FetchAllocations(m.nomadUrl, m.nomadToken, m.jobID)
+		return nomad.FetchAllocations(m.pageCtx, m.client, m.jobID, m.namespace, m.datacenter)
 	case nomad.AllocSpecPage:
-		return nomad.FetchAllocSpec(m.nomadUrl, m.nomadToken, m.allocID)
+		return nomad.FetchAllocSpec(m.pageCtx, m.client, m.allocID)
 	case nomad.LogsPage:
-		return nomad.FetchLogs(m.nomadUrl, m.nomadToken, m.allocID, m.taskName, m.logType)
+		// earlier logs loaded for the logs being replaced are dropped
+		m.logsGeneration++
+		m.loadingEarlier = false
+		return nomad.FetchLogs(m.pageCtx, m.client, m.allocID, m.taskName, m.logType, m.logTailBytes, m.logsGeneration)
 	case nomad.LoglinePage:
 		return nomad.FetchLogLine(m.logline)
+	case nomad.AllocFilesPage:
+		return nomad.FetchAllocFiles(m.pageCtx, m.client, m.allocID, m.filesPath)
+	case nomad.AllocFilePage:
+		return nomad.FetchAllocFile(m.pageCtx, m.client, m.allocID, m.filePath, m.logTailBytes)
+	case nomad.TemplatesPage:
+		return nomad.FetchTemplates(m.pageCtx, m.client, m.allocID, m.taskName)
+	case nomad.StatsPage:
+		return nomad.FetchStats(m.pageCtx, m.client, m.allocID)
+	case nomad.NodesPage:
+		return nomad.FetchNodes(m.pageCtx, m.client)
+	case nomad.NodePage:
+		return nomad.FetchNode(m.pageCtx, m.client, m.nodeID)
+	case nomad.ServicesPage:
+		return nomad.FetchServices(m.pageCtx, m.client)
+	case nomad.ServicePage:
+		return nomad.FetchService(m.pageCtx, m.client, m.serviceName, m.namespace)
+	case nomad.VolumesPage:
+		return nomad.FetchVolumes(m.pageCtx, m.client)
+	case nomad.VolumePage:
+		return nomad.FetchVolume(m.pageCtx, m.client, m.volumeID, m.namespace)
+	case nomad.PluginsPage:
+		return nomad.FetchPlugins(m.pageCtx, m.client)
+	case nomad.PluginPage:
+		return nomad.FetchPlugin(m.pageCtx, m.client, m.pluginID)
+	case nomad.TokenPage:
+		return nomad.FetchToken(m.pageCtx, m.client)
+	case nomad.OverviewPage:
+		return nomad.FetchOverview(m.pageCtx, m.client)
+	case nomad.TaskGroupsPage:
+		return nomad.FetchTaskGroups(m.pageCtx, m.client, m.jobID, m.namespace)
+	case nomad.ScalePage:
+		return nomad.FetchScale(m.pageCtx, m.client, m.jobID, m.namespace)
+	case nomad.RegionsPage:
+		return nomad.FetchRegions(m.pageCtx, m.client)
 	default:
 		panic("page load command not found")
 	}
@@ -302,23 +1192,39 @@ func (m model) currentPageLoading() bool {
 	return m.getCurrentPageModel().Loading()
 }
 
-func (m model) currentPageFilterFocused() bool {
-	return m.getCurrentPageModel().FilterFocused()
+func (m model) currentPageTypingInput() bool {
+	return m.getCurrentPageModel().TypingInput()
 }
 
 func (m model) currentPageFilterApplied() bool {
 	return m.getCurrentPageModel().FilterApplied()
 }
 
-func (m model) currentPageViewportSaving() bool {
-	return m.getCurrentPageModel().ViewportSaving()
-}
-
 func (m model) getFilterPrefix(page nomad.Page) string {
-	return page.GetFilterPrefix(m.jobID, m.taskName, m.allocID)
+	filePath := m.filesPath
+	if page == nomad.AllocFilePage {
+		filePath = m.filePath
+	}
+	return page.GetFilterPrefix(nomad.PageContext{
+		JobID:       m.jobID,
+		TaskName:    m.taskName,
+		AllocID:     m.allocID,
+		FilePath:    filePath,
+		NodeID:      m.nodeID,
+		ServiceName: m.serviceName,
+		VolumeID:    m.volumeID,
+		PluginID:    m.pluginID,
+		Datacenter:  m.datacenter,
+	})
 }
 
 func main() {
+	cfg := loadConfig()
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], clientFromEnv, os.Stdout, os.Stderr))
+	}
+	applyTheme(cfg)
+
 	program := tea.NewProgram(initialModel(), tea.WithAltScreen())
 
 	dev.Debug("~STARTING UP~")
@@ -327,3 +1233,10 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
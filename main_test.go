@@ -0,0 +1,665 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"wander/components/page"
+	"wander/constants"
+	"wander/nomad"
+	"wander/nomad/nomadtest"
+	"wander/style"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// commandTimeout is how long a command may run before the test fails. Requests to the fake Nomad API finish well
+// within it.
+const commandTimeout = 10 * time.Second
+
+// timers are the code of commands that wait before sending a message, e.g. to hide toasts, poll stats or blink
+// cursors, which are dropped rather than run so tests don't wait for them
+var timers = timerCode()
+
+func timerCode() map[uintptr]bool {
+	input := textinput.New()
+	return map[uintptr]bool{
+		reflect.ValueOf(tea.Tick(0, nil)).Pointer(): true,
+		reflect.ValueOf(input.Focus()).Pointer():    true,
+	}
+}
+
+func isTimer(cmd tea.Cmd) bool {
+	return timers[reflect.ValueOf(cmd).Pointer()]
+}
+
+func TestMain(m *testing.M) {
+	// times are rendered in local time
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// harness drives the root model like a tea.Program would, running the commands it returns until they settle
+type harness struct {
+	t     *testing.T
+	model model
+	quit  bool
+}
+
+func newHarness(t *testing.T, server *nomadtest.Server) *harness {
+	t.Helper()
+	h := &harness{t: t, model: newModel(server.Client(), nomad.JobsPage, constants.DefaultLogTailBytes)}
+	h.send(tea.WindowSizeMsg{Width: 160, Height: 24})
+	return h
+}
+
+// send updates the model with msg, then processes the resulting messages until no commands are left running
+func (h *harness) send(msg tea.Msg) {
+	h.t.Helper()
+	msgs := make(chan tea.Msg, 64)
+	pending := 0
+	run := func(cmd tea.Cmd) {
+		if cmd != nil && !isTimer(cmd) {
+			pending++
+			go func() { msgs <- cmd() }()
+		}
+	}
+
+	run(func() tea.Msg { return msg })
+	for pending > 0 {
+		select {
+		case msg := <-msgs:
+			pending--
+			switch {
+			case msg == nil:
+			case msg == tea.Quit():
+				h.quit = true
+			case isBatch(msg):
+				for _, cmd := range batchCmds(msg) {
+					run(cmd)
+				}
+			default:
+				updated, cmd := h.model.Update(msg)
+				h.model = updated.(model)
+				run(cmd)
+			}
+		case <-time.After(commandTimeout):
+			h.t.Fatalf("%d commands still running after %s", pending, commandTimeout)
+		}
+	}
+}
+
+// keys sends each key in turn, e.g. "enter", "esc", "down" or "p"
+func (h *harness) keys(keys ...string) {
+	h.t.Helper()
+	for _, k := range keys {
+		h.send(keyMsg(k))
+	}
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	case "ctrl+r":
+		return tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "ctrl+x":
+		return tea.KeyMsg{Type: tea.KeyCtrlX}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// tea.Batch returns its commands in an unexported message type
+func isBatch(msg tea.Msg) bool {
+	v := reflect.ValueOf(msg)
+	return v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeOf(tea.Cmd(nil))
+}
+
+func batchCmds(msg tea.Msg) []tea.Cmd {
+	v := reflect.ValueOf(msg)
+	cmds := make([]tea.Cmd, v.Len())
+	for i := range cmds {
+		cmds[i] = v.Index(i).Interface().(tea.Cmd)
+	}
+	return cmds
+}
+
+func (h *harness) assertPage(expected nomad.Page) {
+	h.t.Helper()
+	if h.model.currentPage != expected {
+		h.t.Fatalf("on %s page, expected %s page", h.model.currentPage, expected)
+	}
+}
+
+// assertGolden compares the view to testdata/golden/name.golden
+func (h *harness) assertGolden(name string) {
+	h.t.Helper()
+	assertGolden(h.t, name, trimLines(h.model.View()))
+}
+
+// assertGolden compares actual to testdata/golden/name.golden, rewriting it instead if run with -update
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+	goldenPath := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%v, run with -update to create it", err)
+	}
+	if actual != string(golden) {
+		t.Errorf("output doesn't match %s, run with -update if this is expected\ngot:\n%s\nwant:\n%s", goldenPath, actual, golden)
+	}
+}
+
+// trimLines removes trailing whitespace from each line, so golden files don't depend on padding
+func trimLines(view string) string {
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestJobs(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.assertPage(nomad.JobsPage)
+	h.assertGolden("jobs")
+
+	h.keys("tab")
+	h.assertGolden("jobs_expanded")
+}
+
+func TestJobSpec(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "p")
+	h.assertPage(nomad.JobSpecPage)
+	h.assertGolden("job_spec")
+
+	h.keys("esc")
+	h.assertPage(nomad.JobsPage)
+}
+
+func TestAllocationsAndLogs(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "enter")
+	h.assertPage(nomad.AllocationsPage)
+	h.assertGolden("allocations")
+
+	h.keys("enter")
+	h.assertPage(nomad.LogsPage)
+	h.assertGolden("logs")
+
+	h.keys("e")
+	h.assertGolden("logs_stderr")
+
+	h.keys("esc")
+	h.assertPage(nomad.AllocationsPage)
+	h.keys("esc")
+	h.assertPage(nomad.JobsPage)
+}
+
+// tokens without node:read can't list nodes, so see allocations without their datacenter
+func TestAllocationsWithoutNodeRead(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/nodes", http.StatusForbidden, "Permission denied", 0)
+
+	h := newHarness(t, server)
+	h.keys("down", "enter")
+	h.assertPage(nomad.AllocationsPage)
+	if h.model.err != nil {
+		t.Fatalf("got error %v", h.model.err)
+	}
+	h.assertGolden("allocations_without_node_read")
+}
+
+// a failed poll of resource usage shouldn't end a long watch
+func TestStatsError(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/client/allocation/0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31/stats", http.StatusInternalServerError, "client unreachable", 0)
+
+	h := newHarness(t, server)
+	h.keys("down", "enter", "s")
+	h.assertPage(nomad.StatsPage)
+	if h.model.err != nil {
+		t.Fatalf("got error %v", h.model.err)
+	}
+	if view := h.model.View(); !strings.Contains(view, "Error: polling resource usage") {
+		t.Errorf("expected an error toast, got:\n%s", view)
+	}
+
+	h.model.statsHistory.Add([]nomad.TaskStats{{TaskName: "nginx"}})
+	h.send(nomad.StatsTickMsg{PollID: h.model.statsPollID})
+	if len(h.model.statsHistory["nginx"]) != 1 {
+		t.Errorf("expected the stats history to be kept")
+	}
+	h.assertPage(nomad.StatsPage)
+}
+
+// failing to load earlier logs shouldn't replace the logs with an error
+func TestEarlierLogsError(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "enter", "enter")
+	h.assertPage(nomad.LogsPage)
+
+	h.model.loadingEarlier = true
+	h.send(nomad.EarlierLogsErrorMsg{Err: errors.New("client unreachable"), Generation: h.model.logsGeneration})
+	if h.model.loadingEarlier || h.model.err != nil {
+		t.Fatalf("got loadingEarlier %t and error %v", h.model.loadingEarlier, h.model.err)
+	}
+	if view := h.model.View(); !strings.Contains(view, "Error: loading earlier logs: client unreachable") {
+		t.Errorf("expected an error toast, got:\n%s", view)
+	}
+	h.assertPage(nomad.LogsPage)
+}
+
+// earlier logs loaded for logs that have since been reloaded shouldn't be shown
+func TestStaleEarlierLogsDropped(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "enter", "enter")
+	h.assertPage(nomad.LogsPage)
+	stale := h.model.logs
+	staleGeneration := h.model.logsGeneration
+
+	h.keys("r")
+	if h.model.logsGeneration == staleGeneration {
+		t.Fatalf("expected reloading the logs to start a new load")
+	}
+	view := h.model.View()
+
+	h.model.loadingEarlier = true
+	h.send(nomad.LogsLoadedMsg{
+		Logs:          stale,
+		Generation:    staleGeneration,
+		TableHeader:   []string{"Stale"},
+		AllPageData:   []page.Row{{Key: "stale", Row: "stale line"}},
+		Earlier:       true,
+		PrependedRows: 1,
+	})
+	h.send(nomad.EarlierLogsErrorMsg{Err: errors.New("client unreachable"), Generation: staleGeneration})
+	if !h.model.loadingEarlier {
+		t.Errorf("expected stale results not to finish loading earlier logs")
+	}
+	if got := h.model.View(); got != view {
+		t.Errorf("expected stale results to be dropped, got:\n%s", got)
+	}
+}
+
+// files that aren't text can't be shown, so opening one goes back to the files with a toast
+func TestAllocFileNotText(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "enter", "F")
+	h.assertPage(nomad.AllocFilesPage)
+	h.keys("enter")
+	h.assertPage(nomad.AllocFilesPage)
+	if h.model.err != nil {
+		t.Fatalf("got error %v", h.model.err)
+	}
+	if view := h.model.View(); !strings.Contains(view, "Error: app.bin isn't a text file") {
+		t.Errorf("expected an error toast, got:\n%s", view)
+	}
+
+	h.keys("down", "enter")
+	h.assertPage(nomad.AllocFilePage)
+	if view := h.model.View(); !strings.Contains(view, "workers 4") {
+		t.Errorf("expected the file's contents, got:\n%s", view)
+	}
+}
+
+// the datacenter filter and expanded jobs of one region don't apply to another
+func TestRegionSwitchResetsDatacenter(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.model.datacenter = "dc1"
+	h.model.expandedJobs["report"] = true
+	h.keys("w")
+	h.assertPage(nomad.RegionsPage)
+	h.keys("enter")
+	h.assertPage(nomad.JobsPage)
+	if h.model.region != "europe" {
+		t.Fatalf("got region %q, expected europe", h.model.region)
+	}
+	if h.model.datacenter != "" || len(h.model.expandedJobs) != 0 {
+		t.Errorf("got datacenter %q and expanded jobs %v, expected them reset", h.model.datacenter, h.model.expandedJobs)
+	}
+	if prefix := h.model.getFilterPrefix(nomad.JobsPage); strings.Contains(prefix, "dc1") {
+		t.Errorf("expected the datacenter out of the filter prefix, got %q", prefix)
+	}
+}
+
+// a page that finishes loading after it's been left shouldn't be shown or style the current page
+func TestPageLoadedAfterLeaving(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	view := h.model.View()
+	h.send(nomad.PageLoadedMsg{
+		Page:        nomad.TaskGroupsPage,
+		TableHeader: []string{"Task Group"},
+		AllPageData: []page.Row{{Key: "web", Row: "web"}},
+		RowStyles:   map[string]lipgloss.Style{"report": style.Warning},
+	})
+	h.assertPage(nomad.JobsPage)
+	if got := h.model.View(); got != view {
+		t.Errorf("expected the jobs page unchanged, got:\n%s", got)
+	}
+}
+
+func TestSave(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+
+	h := newHarness(t, server)
+	save := func(fileName string, allRows bool) string {
+		t.Helper()
+		h.keys("ctrl+s")
+		if allRows {
+			h.keys("tab")
+		}
+		h.keys(filepath.Join(dir, fileName), "enter")
+		saved, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(saved)
+	}
+
+	assertGolden(t, "save_jobs_csv", save("jobs.csv", false))
+	assertGolden(t, "save_jobs_md", save("jobs.md", false))
+
+	h.keys("/", "w", "e", "b", "enter")
+	assertGolden(t, "save_jobs_filtered_tsv", save("filtered.tsv", false))
+	assertGolden(t, "save_jobs_all_tsv", save("all.tsv", true))
+
+	h.keys("esc", "down", "enter")
+	h.assertPage(nomad.AllocationsPage)
+	assertGolden(t, "save_allocations_json", save("allocations.json", false))
+}
+
+func TestAllocSpec(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("down", "enter", "p")
+	h.assertPage(nomad.AllocSpecPage)
+	h.assertGolden("alloc_spec")
+
+	h.keys("esc")
+	h.assertPage(nomad.AllocationsPage)
+}
+
+// permissionsFor returns the permissions of a token with a policy granting rules, a json list of namespace rules
+func permissionsFor(t *testing.T, rules string) nomad.Permissions {
+	t.Helper()
+	acl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/acl/token/self":
+			_, _ = w.Write([]byte(`{"Type": "client", "Policies": ["test"]}`))
+		case "/v1/acl/policy/test":
+			_, _ = w.Write([]byte(`{"Name": "test", "RulesJSON": {"Namespaces": ` + rules + `}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer acl.Close()
+	return nomad.FetchPermissions(context.Background(), nomad.NewClient(acl.URL, ""))().(nomad.PermissionsLoadedMsg).Permissions
+}
+
+// bulk actions are only taken on rows in namespaces the token has the capability in
+func TestBulkActionsCheckNamespaces(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.model.permissions = permissionsFor(t, `[{"Name": "dev", "Policy": "write"}]`)
+	h.keys("ctrl+x")
+	if h.model.prompt.Active() {
+		t.Fatal("expected no confirmation to stop jobs in the default namespace")
+	}
+	if view := h.model.View(); !strings.Contains(view, "token lacks submit-job in namespace default") {
+		t.Errorf("expected a permission toast, got:\n%s", view)
+	}
+
+	rows := []page.Row{{Key: "web default"}, {Key: "api dev"}, {Key: "batch prod"}}
+	permitted, denied := h.model.permittedRows(rows, nomad.CapabilitySubmitJob, nomad.JobIDAndNamespaceFromKey)
+	if len(permitted) != 1 || permitted[0].Key != "api dev" {
+		t.Errorf("got permitted %v, expected only api", permitted)
+	}
+	if !reflect.DeepEqual(denied, []string{"default", "prod"}) {
+		t.Errorf("got denied namespaces %v", denied)
+	}
+
+	h.model.permissions = permissionsFor(t, `[{"Name": "default", "Policy": "read", "Capabilities": ["alloc-lifecycle"]}]`)
+	h.keys("down", "enter", "ctrl+r")
+	if !h.model.prompt.Active() {
+		t.Fatal("expected a confirmation to restart the allocation")
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/jobs", 500, "rpc error: no cluster leader", 0)
+
+	h := newHarness(t, server)
+	h.assertGolden("error")
+}
+
+func TestNotFound(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	// the fixtures only have a spec for the web job
+	h := newHarness(t, server)
+	h.keys("p")
+	if h.model.err == nil || !strings.Contains(h.model.err.Error(), "404") {
+		t.Errorf("expected a not found error, got %v", h.model.err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	var copied []string
+	h.model.copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	assertCopied := func(expected string) {
+		t.Helper()
+		if len(copied) == 0 || copied[len(copied)-1] != expected {
+			t.Fatalf("expected %q copied, got %q", expected, copied)
+		}
+	}
+
+	h.keys("down", "y")
+	assertCopied("web")
+
+	h.keys("enter", "y")
+	assertCopied("0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31")
+	h.assertGolden("copy_toast")
+
+	h.keys("Y")
+	assertCopied("0b9c6a5e    frontend      web.frontend[0]    nginx        running    running         dc1           2022-06-01T00:00:05    -")
+
+	h.keys("enter", "y")
+	assertCopied("2022-06-01T00:02:00Z GET /health 200")
+
+	h.keys("C")
+	assertCopied(h.model.logsPage.FilteredText())
+}
+
+func TestMarks(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	var copied []string
+	h.model.copyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+
+	h.keys("x", "down", "x")
+	h.assertGolden("jobs_marked")
+	h.keys("y")
+	if expected := "report\nweb"; len(copied) != 1 || copied[0] != expected {
+		t.Errorf("expected %q copied, got %q", expected, copied)
+	}
+
+	h.keys("ctrl+x", "y")
+	if writes := strings.Join(server.Writes(), ", "); writes != "DELETE /v1/job/report, DELETE /v1/job/web" {
+		t.Errorf("expected both jobs stopped, got %s", writes)
+	}
+	if h.model.jobsPage.HasMarks() {
+		t.Error("expected marks cleared after stopping jobs")
+	}
+
+	h.keys("down", "enter", "v", "J", "esc")
+	h.assertPage(nomad.AllocationsPage)
+	if h.model.allocationsPage.HasMarks() {
+		t.Error("expected esc to clear marks")
+	}
+
+	h.keys("g", "J", "ctrl+r", "y")
+	expected := "DELETE /v1/job/report, DELETE /v1/job/web, " +
+		"POST /v1/client/allocation/0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31/restart, " +
+		"POST /v1/client/allocation/5d3e1b7f-8a2c-4e6d-b0f9-3c4d5e6f7a8b/restart"
+	if writes := strings.Join(server.Writes(), ", "); writes != expected {
+		t.Errorf("expected both allocations restarted, got %s", writes)
+	}
+}
+
+func TestJump(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	assertSelected := func(expected string) {
+		t.Helper()
+		if row, err := h.model.logsPage.GetSelectedPageRow(); err != nil || strings.TrimSpace(row.Row) != expected {
+			t.Fatalf("expected %q selected, got %q", expected, row.Row)
+		}
+	}
+
+	h.keys("down", "enter", "enter")
+	h.assertPage(nomad.LogsPage)
+	h.keys(":", "2", "enter")
+	assertSelected("2022-06-01T00:00:06Z listening on :8080")
+
+	h.keys(":", "0", "0", ":", "0", "1", "enter")
+	assertSelected("2022-06-01T00:01:00Z GET / 200")
+
+	h.keys("m", "a", "G", "'", "a")
+	assertSelected("2022-06-01T00:01:00Z GET / 200")
+
+	h.keys(":", "9", "enter")
+	if !strings.Contains(h.model.View(), "no line 9") {
+		t.Error("expected an error toast for a line past the end")
+	}
+
+	h.keys(":", "q")
+	if h.quit {
+		t.Error("expected q to be typed in the jump dialog")
+	}
+	h.keys("esc")
+	h.assertPage(nomad.LogsPage)
+}
+
+func TestHelp(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("?")
+	h.assertGolden("help_jobs")
+
+	// scrolls in small terminals
+	h.send(tea.WindowSizeMsg{Width: 80, Height: 10})
+	h.keys("G")
+	h.assertGolden("help_jobs_small")
+
+	h.keys("?")
+	if h.model.showHelp {
+		t.Fatal("expected ? to close help")
+	}
+	h.assertPage(nomad.JobsPage)
+
+	// esc clears the help's filter before closing it
+	h.keys("down", "enter", "?", "/", "m", "a", "r", "k", "enter", "esc")
+	if !h.model.showHelp {
+		t.Fatal("expected esc to clear the filter first")
+	}
+	h.keys("esc")
+	if h.model.showHelp {
+		t.Fatal("expected esc to close help")
+	}
+	h.assertPage(nomad.AllocationsPage)
+}
+
+func TestExit(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	h := newHarness(t, server)
+	h.keys("q")
+	if !h.quit {
+		t.Error("expected q to exit")
+	}
+}
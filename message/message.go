@@ -3,3 +3,13 @@ package message
 type ErrMsg struct{ Err error }
 
 func (e ErrMsg) Error() string { return e.Err.Error() }
+
+// ActionStatusMsg reports the outcome of an action taken against the cluster, e.g. draining a node
+type ActionStatusMsg struct {
+	SuccessMessage, Err string
+}
+
+// CopyStatusMsg reports the outcome of copying to the clipboard
+type CopyStatusMsg struct {
+	SuccessMessage, Err string
+}
@@ -0,0 +1,294 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"wander/components/page"
+	"wander/formatter"
+)
+
+// CapabilityCSIWriteVolume is the namespace capability required to detach volumes
+// https://www.nomadproject.io/docs/other-specifications/acl-policy#namespace-rules
+const CapabilityCSIWriteVolume = "csi-write-volume"
+
+// CapabilityDispatchJob is the namespace capability required to dispatch parameterized jobs
+const CapabilityDispatchJob = "dispatch-job"
+
+// CapabilityScaleJob is the namespace capability required to scale task groups
+const CapabilityScaleJob = "scale-job"
+
+// CapabilitySubmitJob is the namespace capability required to stop jobs
+const CapabilitySubmitJob = "submit-job"
+
+// CapabilityAllocLifecycle is the namespace capability required to restart allocations
+const CapabilityAllocLifecycle = "alloc-lifecycle"
+
+var readCapabilities = []string{
+	"list-jobs", "parse-job", "read-job", "csi-list-volume", "csi-read-volume", "list-scaling-policies",
+	"read-scaling-policy", "read-job-scaling",
+}
+
+// namespacePolicyCapabilities expands the coarse namespace policies into the capabilities they grant
+var namespacePolicyCapabilities = map[string][]string{
+	"deny":  {},
+	"read":  readCapabilities,
+	"scale": append([]string{CapabilityScaleJob}, readCapabilities...),
+	"write": append([]string{
+		CapabilitySubmitJob, CapabilityDispatchJob, CapabilityScaleJob, "read-logs", "read-fs", "alloc-exec",
+		CapabilityAllocLifecycle, "csi-mount-volume", CapabilityCSIWriteVolume, "submit-recommendation",
+	}, readCapabilities...),
+}
+
+// aclTokenResponse is returned from GET /v1/acl/token/self
+// https://www.nomadproject.io/api-docs/acl-tokens#read-self-token
+type aclTokenResponse struct {
+	AccessorID     string     `json:"AccessorID"`
+	Name           string     `json:"Name"`
+	Type           string     `json:"Type"`
+	Policies       []string   `json:"Policies"`
+	Global         bool       `json:"Global"`
+	CreateTime     time.Time  `json:"CreateTime"`
+	ExpirationTime *time.Time `json:"ExpirationTime"`
+}
+
+type namespacePolicy struct {
+	Name         string   `json:"Name"`
+	Policy       string   `json:"Policy"`
+	Capabilities []string `json:"Capabilities"`
+}
+
+// aclPolicyResponse is returned from GET /v1/acl/policy/:policy_name
+// https://www.nomadproject.io/api-docs/acl-policies#read-policy
+type aclPolicyResponse struct {
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	Rules       string `json:"Rules"`
+	RulesJSON   *struct {
+		Namespaces []namespacePolicy `json:"Namespaces"`
+		Node       *struct {
+			Policy string `json:"Policy"`
+		} `json:"Node"`
+	} `json:"RulesJSON"`
+}
+
+// Permissions describes what the token in use is allowed to do. Actions are allowed unless the token's policies are
+// known to forbid them, so nothing is hidden when ACLs are disabled or policies can't be interpreted.
+type Permissions struct {
+	restricted bool
+	nodeWrite  bool
+	namespaces []namespacePolicy
+}
+
+func (p Permissions) CanWriteNodes() bool {
+	return !p.restricted || p.nodeWrite
+}
+
+// HasNamespaceCapability reports whether the token has capability in namespace. The most specific namespace rule
+// applies: an exact match, otherwise the longest matching glob. Rules for the same namespace in the token's policies
+// are merged, as Nomad does.
+func (p Permissions) HasNamespaceCapability(namespace, capability string) bool {
+	if !p.restricted {
+		return true
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	matching := ""
+	for _, rule := range p.namespaces {
+		if rule.Name == namespace {
+			matching = rule.Name
+			break
+		}
+		if matched, _ := path.Match(rule.Name, namespace); matched && len(rule.Name) > len(matching) {
+			matching = rule.Name
+		}
+	}
+	if matching == "" {
+		return false
+	}
+	return namespaceRuleGrants(p.mergedNamespaceRule(matching), capability)
+}
+
+// HasCapabilityInAnyNamespace reports whether the token has capability in at least one namespace
+func (p Permissions) HasCapabilityInAnyNamespace(capability string) bool {
+	if !p.restricted {
+		return true
+	}
+	for _, rule := range p.namespaces {
+		if namespaceRuleGrants(p.mergedNamespaceRule(rule.Name), capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedNamespaceRule combines the rules for the namespace called name from all the token's policies into one, which
+// grants every capability they grant unless one of them denies
+func (p Permissions) mergedNamespaceRule(name string) namespacePolicy {
+	merged := namespacePolicy{Name: name}
+	for _, rule := range p.namespaces {
+		if rule.Name != name {
+			continue
+		}
+		if rule.Policy == "deny" {
+			merged.Capabilities = append(merged.Capabilities, "deny")
+		}
+		merged.Capabilities = append(merged.Capabilities, namespacePolicyCapabilities[rule.Policy]...)
+		merged.Capabilities = append(merged.Capabilities, rule.Capabilities...)
+	}
+	return merged
+}
+
+func namespaceRuleGrants(rule namespacePolicy, capability string) bool {
+	var granted []string
+	granted = append(granted, namespacePolicyCapabilities[rule.Policy]...)
+	granted = append(granted, rule.Capabilities...)
+	for _, c := range granted {
+		if c == "deny" {
+			return false
+		}
+	}
+	for _, c := range granted {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+type PermissionsLoadedMsg struct {
+	Permissions Permissions
+}
+
+// ACLTokenSelf reads the token requests are made with
+// https://www.nomadproject.io/api-docs/acl-tokens#read-self-token
+func (c *Client) ACLTokenSelf(ctx context.Context) (aclTokenResponse, error) {
+	var tokenResponse aclTokenResponse
+	err := c.getJSON(ctx, "/v1/acl/token/self", nil, &tokenResponse)
+	return tokenResponse, err
+}
+
+// ACLPolicy reads an ACL policy
+// https://www.nomadproject.io/api-docs/acl-policies#read-policy
+func (c *Client) ACLPolicy(ctx context.Context, name string) (aclPolicyResponse, error) {
+	var policy aclPolicyResponse
+	err := c.getJSON(ctx, "/v1/acl/policy/"+name, nil, &policy)
+	return policy, err
+}
+
+func FetchPermissions(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		tokenResponse, policies, err := getTokenAndPolicies(ctx, client)
+		if err != nil {
+			// ACLs disabled or token can't be read, so nothing is known to be restricted
+			return PermissionsLoadedMsg{Permissions: Permissions{}}
+		}
+		return PermissionsLoadedMsg{Permissions: permissionsFromPolicies(tokenResponse, policies)}
+	}
+}
+
+func FetchToken(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		tokenResponse, policies, err := getTokenAndPolicies(ctx, client)
+		if err != nil {
+			return PageLoadedMsg{
+				Page:        TokenPage,
+				TableHeader: []string{},
+				AllPageData: []page.Row{{Key: "", Row: fmt.Sprintf("Could not read token: %s", err)}},
+			}
+		}
+
+		return PageLoadedMsg{
+			Page:        TokenPage,
+			TableHeader: []string{},
+			AllPageData: tokenAsSections(tokenResponse, policies, permissionsFromPolicies(tokenResponse, policies)),
+		}
+	}
+}
+
+func getTokenAndPolicies(ctx context.Context, client *Client) (aclTokenResponse, []aclPolicyResponse, error) {
+	// e.g. fails with "ACL support disabled"
+	tokenResponse, err := client.ACLTokenSelf(ctx)
+	if err != nil {
+		return aclTokenResponse{}, nil, err
+	}
+
+	var policies []aclPolicyResponse
+	for _, policyName := range tokenResponse.Policies {
+		policy, err := client.ACLPolicy(ctx, policyName)
+		if err != nil {
+			return aclTokenResponse{}, nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return tokenResponse, policies, nil
+}
+
+func permissionsFromPolicies(tokenResponse aclTokenResponse, policies []aclPolicyResponse) Permissions {
+	if tokenResponse.Type == "management" {
+		return Permissions{}
+	}
+
+	permissions := Permissions{restricted: true}
+	for _, policy := range policies {
+		if policy.RulesJSON == nil {
+			// older Nomad versions don't return parsed rules, so nothing is known to be restricted
+			return Permissions{}
+		}
+		permissions.namespaces = append(permissions.namespaces, policy.RulesJSON.Namespaces...)
+		if node := policy.RulesJSON.Node; node != nil && node.Policy == "write" {
+			permissions.nodeWrite = true
+		}
+	}
+	return permissions
+}
+
+func tokenAsSections(tokenResponse aclTokenResponse, policies []aclPolicyResponse, permissions Permissions) []page.Row {
+	var sections sectionsBuilder
+
+	expires := "never"
+	if tokenResponse.ExpirationTime != nil {
+		expires = formatter.FormatTime(*tokenResponse.ExpirationTime)
+	}
+	sections.addTable("Token", []string{"Field", "Value"}, [][]string{
+		{"Accessor ID", tokenResponse.AccessorID},
+		{"Name", tokenResponse.Name},
+		{"Type", tokenResponse.Type},
+		{"Global", strconv.FormatBool(tokenResponse.Global)},
+		{"Policies", strings.Join(tokenResponse.Policies, ", ")},
+		{"Created", formatter.FormatTime(tokenResponse.CreateTime)},
+		{"Expires", expires},
+	})
+
+	allowed := func(isAllowed bool) string {
+		if isAllowed {
+			return "allowed"
+		}
+		return "not allowed"
+	}
+	sections.addTable("Actions", []string{"Action", "Requires", "Permission"}, [][]string{
+		{"Drain node, toggle eligibility", "node write", allowed(permissions.CanWriteNodes())},
+		{"Detach volume", CapabilityCSIWriteVolume, allowed(permissions.HasCapabilityInAnyNamespace(CapabilityCSIWriteVolume))},
+		{"Dispatch job", CapabilityDispatchJob, allowed(permissions.HasCapabilityInAnyNamespace(CapabilityDispatchJob))},
+		{"Scale task group", CapabilityScaleJob, allowed(permissions.HasCapabilityInAnyNamespace(CapabilityScaleJob))},
+		{"Stop job", CapabilitySubmitJob, allowed(permissions.HasCapabilityInAnyNamespace(CapabilitySubmitJob))},
+		{"Restart allocation", CapabilityAllocLifecycle, allowed(permissions.HasCapabilityInAnyNamespace(CapabilityAllocLifecycle))},
+	})
+
+	for _, policy := range policies {
+		title := fmt.Sprintf("Policy %s", policy.Name)
+		if policy.Description != "" {
+			title += fmt.Sprintf(": %s", policy.Description)
+		}
+		sections.addSection(title, strings.Split(strings.TrimSpace(policy.Rules), "\n"))
+	}
+
+	return sections.rows
+}
@@ -0,0 +1,41 @@
+package nomad
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func permissionsFromPolicyJSON(t *testing.T, policiesJSON string) Permissions {
+	var policies []aclPolicyResponse
+	if err := json.Unmarshal([]byte(policiesJSON), &policies); err != nil {
+		t.Fatal(err)
+	}
+	return permissionsFromPolicies(aclTokenResponse{Type: "client"}, policies)
+}
+
+// rules for the same namespace in different policies are merged, as Nomad does
+func TestHasNamespaceCapabilityMergesPolicies(t *testing.T) {
+	permissions := permissionsFromPolicyJSON(t, `[
+		{"Name": "readers", "RulesJSON": {"Namespaces": [{"Name": "default", "Policy": "read"}, {"Name": "*", "Policy": "write"}]}},
+		{"Name": "writers", "RulesJSON": {"Namespaces": [{"Name": "default", "Policy": "write"}]}},
+		{"Name": "scalers", "RulesJSON": {"Namespaces": [{"Name": "batch", "Capabilities": ["scale-job"]}]}},
+		{"Name": "denied", "RulesJSON": {"Namespaces": [{"Name": "batch", "Policy": "deny"}, {"Name": "ops", "Policy": "read"}]}}
+	]`)
+
+	tests := []struct {
+		namespace, capability string
+		expected              bool
+	}{
+		{"default", CapabilitySubmitJob, true},
+		{"", CapabilitySubmitJob, true},
+		{"batch", CapabilityScaleJob, false},
+		{"ops", CapabilitySubmitJob, false},
+		{"ops", "read-job", true},
+		{"other", CapabilitySubmitJob, true},
+	}
+	for _, test := range tests {
+		if actual := permissions.HasNamespaceCapability(test.namespace, test.capability); actual != test.expected {
+			t.Errorf("%q %s: got %t, expected %t", test.namespace, test.capability, actual, test.expected)
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package nomad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"sort"
 	"strings"
 	"time"
 	"wander/components/page"
+	"wander/dev"
 	"wander/formatter"
 	"wander/message"
 )
@@ -18,6 +19,7 @@ type allocationResponseEntry struct {
 	ID                 string `json:"ID"`
 	EvalID             string `json:"EvalID"`
 	Name               string `json:"Name"`
+	Namespace          string `json:"Namespace"`
 	NodeID             string `json:"NodeID"`
 	PreviousAllocation string `json:"PreviousAllocation"`
 	NextAllocation     string `json:"NextAllocation"`
@@ -72,58 +74,185 @@ type allocationResponseEntry struct {
 
 // allocationRowEntry is an item extracted from allocationResponseEntry
 type allocationRowEntry struct {
-	ID, TaskGroup, Name, TaskName, State string
-	StartedAt, FinishedAt                time.Time
+	ID, TaskGroup, Name, TaskName, State, ClientStatus, Datacenter string
+	StartedAt, FinishedAt                                          time.Time
+	// alloc is shared by the rows of each of its tasks
+	alloc *allocationResponseEntry
 }
 
-func FetchAllocations(url, token, jobID string) tea.Cmd {
+// JobAllocations lists the allocations of a job
+// https://www.nomadproject.io/api-docs/jobs#list-job-allocations
+func (c *Client) JobAllocations(ctx context.Context, jobID, namespace string) ([]allocationResponseEntry, error) {
+	var allocations []allocationResponseEntry
+	err := c.getJSON(ctx, "/v1/job/"+jobID+"/allocations", namespaceParams(namespace), &allocations)
+	return allocations, err
+}
+
+// Allocations lists the allocations in every namespace
+// https://www.nomadproject.io/api-docs/allocations#list-allocations
+func (c *Client) Allocations(ctx context.Context) ([]allocationResponseEntry, error) {
+	var allocations []allocationResponseEntry
+	err := c.getJSON(ctx, "/v1/allocations", map[string]string{"namespace": "*"}, &allocations)
+	return allocations, err
+}
+
+// AllocationsWithPrefix lists the allocations in every namespace with IDs starting with prefix
+// https://www.nomadproject.io/api-docs/allocations#list-allocations
+func (c *Client) AllocationsWithPrefix(ctx context.Context, prefix string) ([]allocationResponseEntry, error) {
+	var allocations []allocationResponseEntry
+	err := c.getJSON(ctx, "/v1/allocations", map[string]string{"prefix": prefix, "namespace": "*"}, &allocations)
+	return allocations, err
+}
+
+// RestartAllocation restarts every task of an allocation in place
+// https://www.nomadproject.io/api-docs/allocations#restart-allocation
+func (c *Client) RestartAllocation(ctx context.Context, allocID string) error {
+	_, err := c.post(ctx, "/v1/client/allocation/"+allocID+"/restart", nil, struct{}{})
+	return err
+}
+
+// RestartAllocations restarts each allocation in allocIDs
+func RestartAllocations(ctx context.Context, client *Client, allocIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		var shortAllocIDs []string
+		var errs []error
+		for _, allocID := range allocIDs {
+			shortAllocIDs = append(shortAllocIDs, formatter.ShortAllocID(allocID))
+			errs = append(errs, client.RestartAllocation(ctx, allocID))
+		}
+		return bulkActionStatus("Restarted", "allocation", shortAllocIDs, errs)
+	}
+}
+
+// FetchAllocations fetches the allocations of a job, or of all jobs if jobID is empty, only including those in
+// datacenter if set
+func FetchAllocations(ctx context.Context, client *Client, jobID, namespace, datacenter string) tea.Cmd {
 	return func() tea.Msg {
-		fullPath := fmt.Sprintf("%s%s%s%s", url, "/v1/job/", jobID, "/allocations")
-		body, err := get(fullPath, token, nil)
+		_, allocationRowEntries, err := getAllocations(ctx, client, jobID, namespace, datacenter)
 		if err != nil {
 			return message.ErrMsg{Err: err}
 		}
 
-		var allocationResponse []allocationResponseEntry
-		if err := json.Unmarshal(body, &allocationResponse); err != nil {
-			return message.ErrMsg{Err: err}
+		tableHeader, allPageData := allocationsAsTable(allocationRowEntries)
+		return PageLoadedMsg{Page: AllocationsPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+// AllocationsTable returns the allocations of a job, or of all jobs if jobID is empty, with a row for each task. Only
+// allocations in datacenter are included if it's set.
+func AllocationsTable(ctx context.Context, client *Client, jobID, namespace, datacenter string) (Table, error) {
+	allocations, allocationRowEntries, err := getAllocations(ctx, client, jobID, namespace, datacenter)
+	if err != nil {
+		return Table{}, err
+	}
+	columns, rows := allocationCells(allocationRowEntries)
+	return Table{Columns: columns, Rows: rows, Objects: allocations}, nil
+}
+
+// getAllocations returns the allocations of a job, or of all jobs if jobID is empty, and the sorted rows of their
+// tasks, only including those in datacenter if set
+func getAllocations(ctx context.Context, client *Client, jobID, namespace, datacenter string) ([]allocationResponseEntry, []allocationRowEntry, error) {
+	var allocationResponse []allocationResponseEntry
+	var err error
+	if jobID == "" {
+		allocationResponse, err = client.Allocations(ctx)
+	} else {
+		allocationResponse, err = client.JobAllocations(ctx, jobID, namespace)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// allocations don't include their datacenter, so it's found from the node they're placed on
+	var nodeIDs []string
+	for _, alloc := range allocationResponse {
+		if alloc.NodeID != "" {
+			nodeIDs = append(nodeIDs, alloc.NodeID)
 		}
+	}
+	nodeDatacenters, err := client.NodeDatacenters(ctx, nodeIDs)
+	if err != nil {
+		// listing nodes needs node:read, so without it datacenters are left blank, and allocations are filtered by
+		// the datacenters their job can run in instead
+		dev.Debug(fmt.Sprintf("datacenters of allocations unknown: %v", err))
+		nodeDatacenters = make(map[string]string)
+	}
+	var jobDatacenters map[string][]string
+	if datacenter != "" && err != nil {
+		if jobDatacenters, err = getJobDatacenters(ctx, client); err != nil {
+			return nil, nil, err
+		}
+	}
 
-		var allocationRowEntries []allocationRowEntry
-		for _, alloc := range allocationResponse {
-			for taskName, task := range alloc.TaskStates {
-				allocationRowEntries = append(allocationRowEntries, allocationRowEntry{
-					ID:         alloc.ID,
-					TaskGroup:  alloc.TaskGroup,
-					Name:       alloc.Name,
-					TaskName:   taskName,
-					State:      task.State,
-					StartedAt:  task.StartedAt.UTC(),
-					FinishedAt: task.FinishedAt.UTC(),
-				})
+	allocations := make([]allocationResponseEntry, 0, len(allocationResponse))
+	var allocationRowEntries []allocationRowEntry
+	for i := range allocationResponse {
+		alloc := &allocationResponse[i]
+		allocDatacenter := nodeDatacenters[alloc.NodeID]
+		if jobDatacenters != nil {
+			if !contains(jobDatacenters[alloc.Namespace+" "+alloc.JobID], datacenter) {
+				continue
 			}
+		} else if datacenter != "" && allocDatacenter != datacenter {
+			continue
 		}
+		allocations = append(allocations, *alloc)
+		for taskName, task := range alloc.TaskStates {
+			allocationRowEntries = append(allocationRowEntries, allocationRowEntry{
+				ID:           alloc.ID,
+				TaskGroup:    alloc.TaskGroup,
+				Name:         alloc.Name,
+				TaskName:     taskName,
+				State:        task.State,
+				ClientStatus: alloc.ClientStatus,
+				Datacenter:   allocDatacenter,
+				StartedAt:    task.StartedAt.UTC(),
+				FinishedAt:   task.FinishedAt.UTC(),
+				alloc:        alloc,
+			})
+		}
+	}
 
-		sort.Slice(allocationRowEntries, func(x, y int) bool {
-			firstTask := allocationRowEntries[x]
-			secondTask := allocationRowEntries[y]
-			if firstTask.TaskName == secondTask.TaskName {
-				if firstTask.Name == secondTask.Name {
-					return firstTask.State > secondTask.State
-				}
-				return firstTask.Name < secondTask.Name
+	sort.Slice(allocationRowEntries, func(x, y int) bool {
+		firstTask := allocationRowEntries[x]
+		secondTask := allocationRowEntries[y]
+		if firstTask.TaskName == secondTask.TaskName {
+			if firstTask.Name == secondTask.Name {
+				return firstTask.State > secondTask.State
 			}
-			return firstTask.TaskName < secondTask.TaskName
-		})
+			return firstTask.Name < secondTask.Name
+		}
+		return firstTask.TaskName < secondTask.TaskName
+	})
+	return allocations, allocationRowEntries, nil
+}
 
-		tableHeader, allPageData := allocationsAsTable(allocationRowEntries)
-		return PageLoadedMsg{Page: AllocationsPage, TableHeader: tableHeader, AllPageData: allPageData}
+// getJobDatacenters returns the datacenters each job can run in, by namespace and job ID separated by a space
+func getJobDatacenters(ctx context.Context, client *Client) (map[string][]string, error) {
+	jobs, err := client.Jobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobDatacenters := make(map[string][]string)
+	for _, job := range jobs {
+		jobDatacenters[job.Namespace+" "+job.ID] = job.Datacenters
 	}
+	return jobDatacenters, nil
 }
 
 func allocationsAsTable(allocations []allocationRowEntry) ([]string, []page.Row) {
-	var allocationResponseRows [][]string
+	columns, allocationResponseRows := allocationCells(allocations)
 	var keys []string
+	var objects []interface{}
+	for _, row := range allocations {
+		keys = append(keys, toAllocationsKey(row))
+		objects = append(objects, row.alloc)
+	}
+	return tableAsRows(columns, allocationResponseRows, keys, objects)
+}
+
+func allocationCells(allocations []allocationRowEntry) ([]string, [][]string) {
+	var allocationResponseRows [][]string
 	for _, row := range allocations {
 		allocationResponseRows = append(allocationResponseRows, []string{
 			formatter.ShortAllocID(row.ID),
@@ -131,28 +260,49 @@ func allocationsAsTable(allocations []allocationRowEntry) ([]string, []page.Row)
 			row.Name,
 			row.TaskName,
 			row.State,
+			row.ClientStatus,
+			row.Datacenter,
 			formatter.FormatTime(row.StartedAt),
 			formatter.FormatTime(row.FinishedAt),
 		})
-		keys = append(keys, toAllocationsKey(row))
 	}
 
-	columns := []string{"Alloc ID", "Task Group", "Alloc Name", "Task Name", "State", "Started", "Finished"}
-	table := formatter.GetRenderedTableAsString(columns, allocationResponseRows)
+	columns := []string{"Alloc ID", "Task Group", "Alloc Name", "Task Name", "State", "Alloc Status", "Datacenter", "Started", "Finished"}
+	return columns, allocationResponseRows
+}
 
-	var rows []page.Row
-	for idx, row := range table.ContentRows {
-		rows = append(rows, page.Row{Key: keys[idx], Row: row})
+// ResolveAllocID returns the full ID of the only allocation with an ID starting with prefix, e.g. a short alloc ID
+func ResolveAllocID(ctx context.Context, client *Client, prefix string) (string, error) {
+	allocations, err := client.AllocationsWithPrefix(ctx, prefix)
+	if err != nil {
+		return "", err
 	}
 
-	return table.HeaderRows, rows
+	var matches []string
+	for _, alloc := range allocations {
+		if strings.HasPrefix(alloc.ID, prefix) {
+			matches = append(matches, alloc.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no allocation with ID %s", prefix)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%d allocations with IDs starting %s", len(matches), prefix)
 }
 
 func toAllocationsKey(allocationRowEntry allocationRowEntry) string {
-	return allocationRowEntry.ID + " " + allocationRowEntry.TaskName
+	return allocationRowEntry.ID + " " + allocationRowEntry.alloc.Namespace + " " + allocationRowEntry.TaskName
 }
 
 func AllocIDAndTaskNameFromKey(key string) (string, string) {
-	split := strings.Split(key, " ")
+	split := strings.SplitN(key, " ", 3)
+	return split[0], split[2]
+}
+
+func AllocIDAndNamespaceFromKey(key string) (string, string) {
+	split := strings.SplitN(key, " ", 3)
 	return split[0], split[1]
 }
@@ -0,0 +1,152 @@
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// allocFileResponseEntry is returned from GET /v1/client/fs/ls/:alloc_id
+// https://www.nomadproject.io/api-docs/client#list-files
+type allocFileResponseEntry struct {
+	Name     string    `json:"Name"`
+	IsDir    bool      `json:"IsDir"`
+	Size     int64     `json:"Size"`
+	FileMode string    `json:"FileMode"`
+	ModTime  time.Time `json:"ModTime"`
+}
+
+// AllocFiles lists the files in a directory of an allocation
+// https://www.nomadproject.io/api-docs/client#list-files
+func (c *Client) AllocFiles(ctx context.Context, allocID, dirPath string) ([]allocFileResponseEntry, error) {
+	var allocFiles []allocFileResponseEntry
+	err := c.getJSON(ctx, "/v1/client/fs/ls/"+allocID, map[string]string{"path": dirPath}, &allocFiles)
+	return allocFiles, err
+}
+
+// AllocFile reads up to limit bytes of a file in an allocation
+// https://www.nomadproject.io/api-docs/client#read-file
+func (c *Client) AllocFile(ctx context.Context, allocID, filePath string, limit int) ([]byte, error) {
+	return c.getLimited(ctx, "/v1/client/fs/cat/"+allocID, map[string]string{"path": filePath}, limit)
+}
+
+// TruncatedKey is the row key of the note that a file is longer than what's shown
+const TruncatedKey = "truncated"
+
+// AllocFileNotTextMsg reports that a file can't be shown as it isn't text
+type AllocFileNotTextMsg struct {
+	FilePath string
+}
+
+// isText reports whether contents look like text, going by whether the start of them has any NUL bytes
+func isText(contents []byte) bool {
+	const sniffBytes = 8000
+	if len(contents) > sniffBytes {
+		contents = contents[:sniffBytes]
+	}
+	return bytes.IndexByte(contents, 0) < 0
+}
+
+func FetchAllocFiles(ctx context.Context, client *Client, allocID, dirPath string) tea.Cmd {
+	return func() tea.Msg {
+		allocFilesResponse, err := client.AllocFiles(ctx, allocID, dirPath)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(allocFilesResponse, func(x, y int) bool {
+			firstFile := allocFilesResponse[x]
+			secondFile := allocFilesResponse[y]
+			if firstFile.IsDir == secondFile.IsDir {
+				return firstFile.Name < secondFile.Name
+			}
+			return firstFile.IsDir
+		})
+
+		tableHeader, allPageData := allocFilesAsTable(allocFilesResponse, dirPath)
+		return PageLoadedMsg{Page: AllocFilesPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+// FetchAllocFile loads the first maxBytes bytes of a file, noting on the page if the file is longer
+func FetchAllocFile(ctx context.Context, client *Client, allocID, filePath string, maxBytes int) tea.Cmd {
+	return func() tea.Msg {
+		// read a byte more than is shown to tell if the file is longer
+		body, err := client.AllocFile(ctx, allocID, filePath, maxBytes+1)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+		if !isText(body) {
+			return AllocFileNotTextMsg{FilePath: filePath}
+		}
+
+		truncated := len(body) > maxBytes
+		if truncated {
+			body = body[:maxBytes]
+			if lastNewline := bytes.LastIndexByte(body, '\n'); lastNewline >= 0 {
+				body = body[:lastNewline]
+			}
+		}
+
+		var allocFilePageData []page.Row
+		for _, row := range strings.Split(string(body), "\n") {
+			allocFilePageData = append(allocFilePageData, page.Row{Key: "", Row: row})
+		}
+		if truncated {
+			allocFilePageData = append(allocFilePageData, page.Row{
+				Key: TruncatedKey,
+				Row: fmt.Sprintf("[truncated: only the first %s are shown]", formatter.FormatBytes(int64(maxBytes))),
+			})
+		}
+
+		return PageLoadedMsg{
+			Page:        AllocFilePage,
+			TableHeader: []string{},
+			AllPageData: allocFilePageData,
+		}
+	}
+}
+
+func allocFilesAsTable(allocFiles []allocFileResponseEntry, dirPath string) ([]string, []page.Row) {
+	var allocFileResponseRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range allocFiles {
+		name := row.Name
+		if row.IsDir {
+			name += "/"
+		}
+		allocFileResponseRows = append(allocFileResponseRows, []string{
+			name,
+			formatter.FormatBytes(row.Size),
+			row.FileMode,
+			formatter.FormatTime(row.ModTime),
+		})
+		keys = append(keys, toAllocFilesKey(path.Join(dirPath, row.Name), row.IsDir))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"Name", "Size", "Mode", "Modified"}
+	tableHeader, rows := tableAsRows(columns, allocFileResponseRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func toAllocFilesKey(filePath string, isDir bool) string {
+	return strconv.FormatBool(isDir) + " " + filePath
+}
+
+func FilePathAndIsDirFromKey(key string) (string, bool) {
+	split := strings.SplitN(key, " ", 2)
+	isDir, _ := strconv.ParseBool(split[0])
+	return split[1], isDir
+}
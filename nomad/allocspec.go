@@ -1,17 +1,22 @@
 package nomad
 
 import (
-	"fmt"
+	"context"
 	tea "github.com/charmbracelet/bubbletea"
 	"wander/components/page"
 	"wander/formatter"
 	"wander/message"
 )
 
-func FetchAllocSpec(url, token, allocID string) tea.Cmd {
+// Allocation returns the json spec of an allocation
+// https://www.nomadproject.io/api-docs/allocations#read-allocation
+func (c *Client) Allocation(ctx context.Context, allocID string) ([]byte, error) {
+	return c.get(ctx, "/v1/allocation/"+allocID, nil)
+}
+
+func FetchAllocSpec(ctx context.Context, client *Client, allocID string) tea.Cmd {
 	return func() tea.Msg {
-		fullPath := fmt.Sprintf("%s%s%s", url, "/v1/allocation/", allocID)
-		body, err := get(fullPath, token, nil)
+		body, err := client.Allocation(ctx, allocID)
 		if err != nil {
 			return message.ErrMsg{Err: err}
 		}
@@ -0,0 +1,195 @@
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a single request attempt may take
+	DefaultTimeout = time.Second * 30
+	// DefaultMaxRetries is how many times a request is retried after a transient error
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the wait before the first retry, doubling for each retry after
+	DefaultRetryBackoff = time.Millisecond * 200
+)
+
+// Client makes requests to the Nomad HTTP API. It keeps one connection pool for all requests, so should be shared.
+// Requests are made to the region set with SetRegion, or the agent's own region if unset.
+type Client struct {
+	url, token string
+	// HTTPClient sends every request, so can be replaced to e.g. use a custom transport
+	HTTPClient   *http.Client
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	mu              sync.RWMutex
+	region          string
+	nodeDatacenters map[string]string
+}
+
+func NewClient(url, token string) *Client {
+	return &Client{
+		url:             strings.TrimSuffix(url, "/"),
+		token:           token,
+		HTTPClient:      &http.Client{},
+		Timeout:         DefaultTimeout,
+		MaxRetries:      DefaultMaxRetries,
+		RetryBackoff:    DefaultRetryBackoff,
+		nodeDatacenters: make(map[string]string),
+	}
+}
+
+// URL is the address of the Nomad agent requests are made to
+func (c *Client) URL() string {
+	return c.url
+}
+
+func (c *Client) Region() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.region
+}
+
+// SetRegion sets the region every subsequent request is made to
+func (c *Client) SetRegion(region string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.region = region
+}
+
+// APIError is returned for responses with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// transient reports whether a request that got this status code may succeed if retried
+func transient(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (c *Client) get(ctx context.Context, path string, params map[string]string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, params, nil, 0)
+}
+
+// getLimited gets path, reading no more than limit bytes of the response, e.g. to stop a stream early
+func (c *Client) getLimited(ctx context.Context, path string, params map[string]string, limit int) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, params, nil, limit)
+}
+
+// getJSON gets path, decoding the json response into v
+func (c *Client) getJSON(ctx context.Context, path string, params map[string]string, v interface{}) error {
+	body, err := c.get(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// post sends payload as json to path
+func (c *Client) post(ctx context.Context, path string, params map[string]string, payload interface{}) ([]byte, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, http.MethodPost, path, params, jsonPayload, 0)
+}
+
+func (c *Client) del(ctx context.Context, path string, params map[string]string) ([]byte, error) {
+	return c.do(ctx, http.MethodDelete, path, params, nil, 0)
+}
+
+// do sends a request, retrying with backoff after transient errors. Only GET requests are retried, as others may
+// have taken effect before failing. A limit above 0 is the most bytes of a successful response to read.
+func (c *Client) do(ctx context.Context, method, path string, params map[string]string, payload []byte, limit int) ([]byte, error) {
+	backoff := c.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		body, retryable, err := c.attempt(ctx, method, path, params, payload, limit)
+		if err == nil || !retryable || method != http.MethodGet || attempt >= c.MaxRetries {
+			return body, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends a request once, returning whether any error is transient
+func (c *Client) attempt(ctx context.Context, method, path string, params map[string]string, payload []byte, limit int) ([]byte, bool, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("X-Nomad-Token", c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	query := req.URL.Query()
+	if region := c.Region(); region != "" {
+		query.Set("region", region)
+	}
+	for key, val := range params {
+		query.Set(key, val)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		// the caller cancelling isn't transient, but a connection error or the attempt timing out is
+		return nil, !errors.Is(err, context.Canceled), err
+	}
+	defer resp.Body.Close()
+
+	reader := io.Reader(resp.Body)
+	if limit > 0 && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		reader = io.LimitReader(resp.Body, int64(limit))
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, !errors.Is(err, context.Canceled), err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
+		return nil, transient(resp.StatusCode), apiErr
+	}
+	return body, false, nil
+}
+
+// namespaceParams scopes a request to namespace, or to the agent's default namespace if namespace is empty
+func namespaceParams(namespace string) map[string]string {
+	if namespace == "" {
+		return nil
+	}
+	return map[string]string{"namespace": namespace}
+}
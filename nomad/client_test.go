@@ -0,0 +1,268 @@
+package nomad_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"wander/message"
+	"wander/nomad"
+	"wander/nomad/nomadtest"
+)
+
+func TestClientGet(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	jobs, err := server.Client().Jobs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 4 {
+		t.Errorf("got %d jobs, expected 4", len(jobs))
+	}
+}
+
+func TestClientRetriesTransientErrors(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/jobs", http.StatusServiceUnavailable, "no cluster leader", 2)
+
+	if _, err := server.Client().Jobs(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.Requests("/v1/jobs"); requests != 3 {
+		t.Errorf("got %d requests, expected 3", requests)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/jobs", http.StatusBadGateway, "bad gateway", 0)
+
+	client := server.Client()
+	_, err := client.Jobs(context.Background())
+	var apiErr nomad.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("got %v, expected a bad gateway error", err)
+	}
+	if requests := server.Requests("/v1/jobs"); requests != client.MaxRetries+1 {
+		t.Errorf("got %d requests, expected %d", requests, client.MaxRetries+1)
+	}
+}
+
+func TestClientDoesNotRetryErrors(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().Job(context.Background(), "missing", "")
+	var apiErr nomad.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("got %v, expected a not found error", err)
+	}
+	if requests := server.Requests("/v1/job/missing"); requests != 1 {
+		t.Errorf("got %d requests, expected 1", requests)
+	}
+}
+
+// writes may have taken effect before failing, so are never retried
+func TestClientDoesNotRetryWrites(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/job/web/scale", http.StatusServiceUnavailable, "no cluster leader", 0)
+
+	if err := server.Client().Scale(context.Background(), "web", "", "frontend", 3, ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if requests := server.Requests("/v1/job/web/scale"); requests != 1 {
+		t.Errorf("got %d requests, expected 1", requests)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	client := nomad.NewClient(server.URL, "wrong-token")
+	_, err := client.Jobs(context.Background())
+	var apiErr nomad.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("got %v, expected a forbidden error", err)
+	}
+}
+
+func TestClientCancelled(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := server.Client().Jobs(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, expected context.Canceled", err)
+	}
+	if requests := server.Requests("/v1/jobs"); requests != 0 {
+		t.Errorf("got %d requests, expected 0", requests)
+	}
+}
+
+// disabling drain should make the node eligible again, as `nomad node drain -disable` does
+func TestSetNodeDrain(t *testing.T) {
+	tests := []struct {
+		enable       bool
+		markEligible bool
+		drainSpec    bool
+	}{
+		{enable: true, markEligible: false, drainSpec: true},
+		{enable: false, markEligible: true, drainSpec: false},
+	}
+	for _, test := range tests {
+		server := nomadtest.NewServer()
+		msg := nomad.SetNodeDrain(context.Background(), server.Client(), "node-1", test.enable, time.Hour)()
+		server.Close()
+		if status, ok := msg.(message.ActionStatusMsg); !ok || status.Err != "" {
+			t.Fatalf("enable %t: got %#v", test.enable, msg)
+		}
+
+		var body struct {
+			DrainSpec    map[string]interface{}
+			MarkEligible bool
+		}
+		if err := json.Unmarshal(server.WriteBody("/v1/node/node-1/drain"), &body); err != nil {
+			t.Fatalf("enable %t: %v", test.enable, err)
+		}
+		if body.MarkEligible != test.markEligible {
+			t.Errorf("enable %t: got MarkEligible %t, expected %t", test.enable, body.MarkEligible, test.markEligible)
+		}
+		if (body.DrainSpec != nil) != test.drainSpec {
+			t.Errorf("enable %t: got DrainSpec %v", test.enable, body.DrainSpec)
+		}
+	}
+}
+
+func TestNodeDatacentersCached(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for i := 0; i < 2; i++ {
+		table, err := nomad.AllocationsTable(context.Background(), client, "", "", "dc2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(table.Rows) == 0 {
+			t.Fatal("expected allocations in dc2")
+		}
+	}
+	if requests := server.Requests("/v1/nodes"); requests != 1 {
+		t.Errorf("got %d requests for nodes, expected 1", requests)
+	}
+}
+
+// without a region set, requests go to the agent's region, so it's the current one
+func TestFetchRegionsMarksAgentRegion(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	msg := nomad.FetchRegions(context.Background(), server.Client())()
+	loaded, ok := msg.(nomad.PageLoadedMsg)
+	if !ok {
+		t.Fatalf("got %#v", msg)
+	}
+	if len(loaded.AllPageData) != 2 {
+		t.Fatalf("got %d regions, expected 2", len(loaded.AllPageData))
+	}
+	for _, row := range loaded.AllPageData {
+		if current := strings.Contains(row.Row, "current"); current != (row.Key == "global") {
+			t.Errorf("region %s: got current %t", row.Key, current)
+		}
+	}
+}
+
+// without node:read, allocations are filtered by the datacenters their job can run in
+func TestAllocationsTableWithoutNodeRead(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	server.Fail("/v1/nodes", http.StatusForbidden, "Permission denied", 0)
+
+	tests := []struct {
+		datacenter string
+		expected   int
+	}{
+		{"", 2},
+		{"dc2", 2},
+		{"dc3", 0},
+	}
+	for _, test := range tests {
+		table, err := nomad.AllocationsTable(context.Background(), server.Client(), "web", "", test.datacenter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(table.Rows) != test.expected {
+			t.Errorf("%q: got %d rows, expected %d", test.datacenter, len(table.Rows), test.expected)
+		}
+		for _, row := range table.Rows {
+			if datacenter := row[6]; datacenter != "" {
+				t.Errorf("%q: got datacenter %q, expected it to be unknown", test.datacenter, datacenter)
+			}
+		}
+	}
+}
+
+func TestFetchServicesCountsInstances(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	msg := nomad.FetchServices(context.Background(), server.Client())()
+	loaded, ok := msg.(nomad.PageLoadedMsg)
+	if !ok {
+		t.Fatalf("got %#v", msg)
+	}
+	var rows []string
+	for _, row := range loaded.AllPageData {
+		rows = append(rows, strings.Join(strings.Fields(row.Row), " "))
+	}
+	if expected := []string{"metrics default 0", "web default http,frontend 2"}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("got %q, expected %q", rows, expected)
+	}
+}
+
+func TestFetchAllocFile(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	const allocID = "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31"
+
+	rows := func(msg tea.Msg) []string {
+		t.Helper()
+		loaded, ok := msg.(nomad.PageLoadedMsg)
+		if !ok {
+			t.Fatalf("got %#v", msg)
+		}
+		var rows []string
+		for _, row := range loaded.AllPageData {
+			rows = append(rows, row.Row)
+		}
+		return rows
+	}
+
+	all := rows(nomad.FetchAllocFile(context.Background(), server.Client(), allocID, "/app.conf", 1000)())
+	if expected := []string{"listen 8080", "workers 4", "log_level info", "timeout 30", ""}; !reflect.DeepEqual(all, expected) {
+		t.Errorf("got %q, expected %q", all, expected)
+	}
+
+	// the file is cut at the last whole line within the limit
+	truncated := rows(nomad.FetchAllocFile(context.Background(), server.Client(), allocID, "/app.conf", 30)())
+	if expected := []string{"listen 8080", "workers 4", "[truncated: only the first 30 B are shown]"}; !reflect.DeepEqual(truncated, expected) {
+		t.Errorf("got %q, expected %q", truncated, expected)
+	}
+
+	msg := nomad.FetchAllocFile(context.Background(), server.Client(), allocID, "/app.bin", 1000)()
+	if _, ok := msg.(nomad.AllocFileNotTextMsg); !ok {
+		t.Errorf("got %#v, expected an AllocFileNotTextMsg", msg)
+	}
+}
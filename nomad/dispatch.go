@@ -0,0 +1,197 @@
+package nomad
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"io/ioutil"
+	"strings"
+	"wander/message"
+)
+
+// Payload requirements of a parameterized job
+// https://www.nomadproject.io/docs/job-specification/parameterized#payload
+const (
+	PayloadOptional  = "optional"
+	PayloadRequired  = "required"
+	PayloadForbidden = "forbidden"
+)
+
+// parameterizedJobResponse is the part of GET /v1/job/:job_id needed to dispatch it
+type parameterizedJobResponse struct {
+	ParameterizedJob *struct {
+		Payload      string   `json:"Payload"`
+		MetaRequired []string `json:"MetaRequired"`
+		MetaOptional []string `json:"MetaOptional"`
+	} `json:"ParameterizedJob"`
+}
+
+// DispatchSpec describes the meta keys and payload a parameterized job accepts
+type DispatchSpec struct {
+	JobID, Namespace           string
+	MetaRequired, MetaOptional []string
+	Payload                    string
+}
+
+type DispatchSpecLoadedMsg struct {
+	Spec DispatchSpec
+}
+
+type JobDispatchedMsg struct {
+	JobID, DispatchedJobID, Namespace string
+}
+
+func FetchDispatchSpec(ctx context.Context, client *Client, jobID, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		body, err := client.Job(ctx, jobID, namespace)
+		if err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+
+		var jobResponse parameterizedJobResponse
+		if err := json.Unmarshal(body, &jobResponse); err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+		if jobResponse.ParameterizedJob == nil {
+			return message.ActionStatusMsg{Err: fmt.Sprintf("job %s is not parameterized", jobID)}
+		}
+
+		payload := jobResponse.ParameterizedJob.Payload
+		if payload == "" {
+			payload = PayloadOptional
+		}
+		return DispatchSpecLoadedMsg{Spec: DispatchSpec{
+			JobID:        jobID,
+			Namespace:    namespace,
+			MetaRequired: jobResponse.ParameterizedJob.MetaRequired,
+			MetaOptional: jobResponse.ParameterizedJob.MetaOptional,
+			Payload:      payload,
+		}}
+	}
+}
+
+// DispatchForm collects the meta values and payload file for a dispatch one field at a time
+type DispatchForm struct {
+	Spec        DispatchSpec
+	Meta        map[string]string
+	PayloadFile string
+	field       int
+}
+
+func NewDispatchForm(spec DispatchSpec) DispatchForm {
+	return DispatchForm{Spec: spec, Meta: make(map[string]string)}
+}
+
+// metaKeys returns the required then optional meta keys, in the order they are asked for
+func (f DispatchForm) metaKeys() []string {
+	return append(append([]string{}, f.Spec.MetaRequired...), f.Spec.MetaOptional...)
+}
+
+// Done reports whether every meta key and the payload file have been asked for
+func (f DispatchForm) Done() bool {
+	numFields := len(f.metaKeys())
+	if f.Spec.Payload != PayloadForbidden {
+		numFields++
+	}
+	return f.field >= numFields
+}
+
+// Question returns the prompt for the current field
+func (f DispatchForm) Question() string {
+	if metaKeys := f.metaKeys(); f.field < len(metaKeys) {
+		requirement := "optional"
+		if f.field < len(f.Spec.MetaRequired) {
+			requirement = "required"
+		}
+		return fmt.Sprintf("Meta %s (%s):", metaKeys[f.field], requirement)
+	}
+	return fmt.Sprintf("Payload file path (%s):", f.Spec.Payload)
+}
+
+// Set validates and records the value for the current field, advancing to the next
+func (f *DispatchForm) Set(value string) error {
+	value = strings.TrimSpace(value)
+	if metaKeys := f.metaKeys(); f.field < len(metaKeys) {
+		metaKey := metaKeys[f.field]
+		if value == "" && f.field < len(f.Spec.MetaRequired) {
+			return fmt.Errorf("meta %s is required", metaKey)
+		}
+		if value != "" {
+			f.Meta[metaKey] = value
+		}
+	} else {
+		if value == "" && f.Spec.Payload == PayloadRequired {
+			return errors.New("payload is required")
+		}
+		f.PayloadFile = value
+	}
+	f.field++
+	return nil
+}
+
+// Summary describes what will be dispatched, for confirmation
+func (f DispatchForm) Summary() string {
+	var parts []string
+	for _, metaKey := range f.metaKeys() {
+		if value, exists := f.Meta[metaKey]; exists {
+			parts = append(parts, fmt.Sprintf("%s=%s", metaKey, value))
+		}
+	}
+	if f.PayloadFile != "" {
+		parts = append(parts, fmt.Sprintf("payload %s", f.PayloadFile))
+	}
+	if len(parts) == 0 {
+		return f.Spec.JobID
+	}
+	return fmt.Sprintf("%s with %s", f.Spec.JobID, strings.Join(parts, ", "))
+}
+
+// Dispatch dispatches a parameterized job, returning the ID of the dispatched child job
+// https://www.nomadproject.io/api-docs/jobs#dispatch-job
+func (c *Client) Dispatch(ctx context.Context, jobID, namespace string, meta map[string]string, payload []byte) (string, error) {
+	request := struct {
+		Meta    map[string]string `json:"Meta,omitempty"`
+		Payload string            `json:"Payload,omitempty"`
+	}{Meta: meta}
+	if len(payload) > 0 {
+		request.Payload = base64.StdEncoding.EncodeToString(payload)
+	}
+
+	body, err := c.post(ctx, "/v1/job/"+jobID+"/dispatch", namespaceParams(namespace), request)
+	if err != nil {
+		return "", err
+	}
+
+	var dispatchResponse struct {
+		DispatchedJobID string `json:"DispatchedJobID"`
+	}
+	err = json.Unmarshal(body, &dispatchResponse)
+	return dispatchResponse.DispatchedJobID, err
+}
+
+// DispatchJob dispatches a parameterized job, reading the payload from a local file if given
+func DispatchJob(ctx context.Context, client *Client, form DispatchForm) tea.Cmd {
+	return func() tea.Msg {
+		var payload []byte
+		if form.PayloadFile != "" {
+			contents, err := ioutil.ReadFile(form.PayloadFile)
+			if err != nil {
+				return message.ActionStatusMsg{Err: err.Error()}
+			}
+			payload = contents
+		}
+
+		dispatchedJobID, err := client.Dispatch(ctx, form.Spec.JobID, form.Spec.Namespace, form.Meta, payload)
+		if err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+		return JobDispatchedMsg{
+			JobID:           form.Spec.JobID,
+			DispatchedJobID: dispatchedJobID,
+			Namespace:       form.Spec.Namespace,
+		}
+	}
+}
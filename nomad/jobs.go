@@ -1,11 +1,13 @@
 package nomad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"sort"
 	"strconv"
+	"strings"
 	"wander/components/page"
 	"wander/formatter"
 	"wander/message"
@@ -26,19 +28,10 @@ type jobResponseEntry struct {
 	Status            string      `json:"Status"`
 	StatusDescription string      `json:"StatusDescription"`
 	JobSummary        struct {
-		JobID     string `json:"JobID"`
-		Namespace string `json:"Namespace"`
-		Summary   struct {
-			YourProjectName struct {
-				Queued   int `json:"Queued"`
-				Complete int `json:"Complete"`
-				Failed   int `json:"Failed"`
-				Running  int `json:"Running"`
-				Starting int `json:"Starting"`
-				Lost     int `json:"Lost"`
-			} `json:"your_project_name"`
-		} `json:"Summary"`
-		Children struct {
+		JobID     string                      `json:"JobID"`
+		Namespace string                      `json:"Namespace"`
+		Summary   map[string]taskGroupSummary `json:"Summary"`
+		Children  struct {
 			Pending int `json:"Pending"`
 			Running int `json:"Running"`
 			Dead    int `json:"Dead"`
@@ -52,66 +45,211 @@ type jobResponseEntry struct {
 	SubmitTime     int64 `json:"SubmitTime"`
 }
 
-func FetchJobs(url, token string) tea.Cmd {
+// Jobs are the jobs listed on the jobs page, kept so the job tree can be re-rendered as it's expanded and collapsed
+type Jobs []jobResponseEntry
+
+type JobsLoadedMsg struct {
+	Jobs Jobs
+}
+
+// Jobs lists the jobs in every namespace
+// https://www.nomadproject.io/api-docs/jobs#list-jobs
+func (c *Client) Jobs(ctx context.Context) ([]jobResponseEntry, error) {
+	var jobs []jobResponseEntry
+	err := c.getJSON(ctx, "/v1/jobs", map[string]string{"namespace": "*"}, &jobs)
+	return jobs, err
+}
+
+// StopJob deregisters a job, stopping its allocations
+// https://www.nomadproject.io/api-docs/jobs#stop-a-job
+func (c *Client) StopJob(ctx context.Context, jobID, namespace string) error {
+	_, err := c.del(ctx, "/v1/job/"+jobID, namespaceParams(namespace))
+	return err
+}
+
+// StopJobs stops the jobs with keys from the jobs page
+func StopJobs(ctx context.Context, client *Client, jobKeys []string) tea.Cmd {
 	return func() tea.Msg {
-		params := map[string]string{
-			"namespace": "*",
+		var jobIDs []string
+		var errs []error
+		for _, jobKey := range jobKeys {
+			jobID, namespace := JobIDAndNamespaceFromKey(jobKey)
+			jobIDs = append(jobIDs, jobID)
+			errs = append(errs, client.StopJob(ctx, jobID, namespace))
 		}
-		fullPath := fmt.Sprintf("%s%s", url, "/v1/jobs")
-		body, err := get(fullPath, token, params)
-		if err != nil {
-			return message.ErrMsg{Err: err}
+		return bulkActionStatus("Stopped", "job", jobIDs, errs)
+	}
+}
+
+// GetJobs lists the jobs in every namespace, sorted by name then namespace
+func GetJobs(ctx context.Context, client *Client) (Jobs, error) {
+	jobResponse, err := client.Jobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobResponse, func(x, y int) bool {
+		firstJob := jobResponse[x]
+		secondJob := jobResponse[y]
+		if firstJob.Name == secondJob.Name {
+			return firstJob.Namespace < secondJob.Namespace
 		}
+		return jobResponse[x].Name < jobResponse[y].Name
+	})
+	return jobResponse, nil
+}
 
-		var jobResponse []jobResponseEntry
-		if err := json.Unmarshal(body, &jobResponse); err != nil {
+func FetchJobs(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		jobs, err := GetJobs(ctx, client)
+		if err != nil {
 			return message.ErrMsg{Err: err}
 		}
+		return JobsLoadedMsg{Jobs: jobs}
+	}
+}
 
-		sort.Slice(jobResponse, func(x, y int) bool {
-			firstJob := jobResponse[x]
-			secondJob := jobResponse[y]
-			if firstJob.Name == secondJob.Name {
-				return firstJob.Namespace < secondJob.Namespace
+// JobsAsTable renders jobs as a tree, with the children of periodic and parameterized jobs listed most recent first
+// under their parent if the parent's key is expanded. Rows are styled by the health of their allocations. Only jobs
+// in datacenter are included if it's set.
+func JobsAsTable(jobs Jobs, expanded map[string]bool, datacenter string) ([]string, []page.Row, map[string]lipgloss.Style) {
+	if datacenter != "" {
+		var inDatacenter Jobs
+		for _, job := range jobs {
+			for _, jobDatacenter := range job.Datacenters {
+				if jobDatacenter == datacenter {
+					inDatacenter = append(inDatacenter, job)
+					break
+				}
 			}
-			return jobResponse[x].Name < jobResponse[y].Name
-		})
+		}
+		jobs = inDatacenter
+	}
 
-		tableHeader, allPageData := jobResponsesAsTable(jobResponse)
-		return PageLoadedMsg{Page: JobsPage, TableHeader: tableHeader, AllPageData: allPageData}
+	children := make(map[string][]jobResponseEntry)
+	exists := make(map[string]bool)
+	for _, job := range jobs {
+		exists[toJobGroupKey(job.ID, job.Namespace)] = true
+	}
+	var topLevel []jobResponseEntry
+	for _, job := range jobs {
+		if parentKey := toJobGroupKey(job.ParentID, job.Namespace); job.ParentID != "" && exists[parentKey] {
+			children[parentKey] = append(children[parentKey], job)
+		} else {
+			topLevel = append(topLevel, job)
+		}
 	}
-}
 
-func jobResponsesAsTable(jobResponse []jobResponseEntry) ([]string, []page.Row) {
 	var jobResponseRows [][]string
 	var keys []string
-	for _, row := range jobResponse {
-		jobResponseRows = append(jobResponseRows, []string{
-			row.ID,
-			row.Type,
-			row.Namespace,
-			strconv.Itoa(row.Priority),
-			row.Status,
-			formatter.FormatTimeNs(row.SubmitTime),
+	var objects []interface{}
+	rowStyles := make(map[string]lipgloss.Style)
+	addRow := func(job jobResponseEntry, prefix string) {
+		jobResponseRows = append(jobResponseRows, jobAsRow(job, prefix))
+		keys = append(keys, toJobsKey(job))
+		objects = append(objects, job)
+		if healthStyle, ok := totalSummary(job.JobSummary.Summary).healthStyle(); ok {
+			rowStyles[toJobsKey(job)] = healthStyle
+		}
+	}
+	for _, job := range topLevel {
+		groupKey := toJobGroupKey(job.ID, job.Namespace)
+		jobChildren := children[groupKey]
+		prefix := ""
+		if len(jobChildren) > 0 {
+			prefix = "▸ "
+			if expanded[groupKey] {
+				prefix = "▾ "
+			}
+		}
+		addRow(job, prefix)
+
+		if !expanded[groupKey] {
+			continue
+		}
+		sort.SliceStable(jobChildren, func(x, y int) bool {
+			return jobChildren[x].SubmitTime > jobChildren[y].SubmitTime
 		})
-		keys = append(keys, toJobsKey(row))
+		for _, child := range jobChildren {
+			addRow(child, "  └ ")
+		}
 	}
 
-	columns := []string{"ID", "Type", "Namespace", "Priority", "Status", "Submit Time"}
-	table := formatter.GetRenderedTableAsString(columns, jobResponseRows)
+	tableHeader, rows := tableAsRows(jobColumns(), jobResponseRows, keys, objects)
 
-	var rows []page.Row
-	for idx, row := range table.ContentRows {
-		rows = append(rows, page.Row{Key: keys[idx], Row: row})
+	return tableHeader, rows, rowStyles
+}
+
+// JobsTable returns every job as a flat table, children included, in the order given
+func JobsTable(jobs Jobs) Table {
+	rows := make([][]string, 0, len(jobs))
+	for _, job := range jobs {
+		rows = append(rows, jobAsRow(job, ""))
 	}
+	return Table{Columns: jobColumns(), Rows: rows, Objects: jobs}
+}
+
+func jobColumns() []string {
+	columns := append([]string{"ID", "Type", "Namespace", "Datacenters", "Priority", "Status"}, summaryColumns...)
+	return append(columns, "Children", "Submit Time")
+}
 
-	return table.HeaderRows, rows
+func jobAsRow(job jobResponseEntry, prefix string) []string {
+	childCounts := ""
+	if counts := job.JobSummary.Children; counts.Pending+counts.Running+counts.Dead > 0 {
+		childCounts = fmt.Sprintf("%d pending, %d running, %d dead", counts.Pending, counts.Running, counts.Dead)
+	}
+	row := []string{
+		prefix + job.ID,
+		job.Type,
+		job.Namespace,
+		strings.Join(job.Datacenters, ","),
+		strconv.Itoa(job.Priority),
+		job.Status,
+	}
+	row = append(row, totalSummary(job.JobSummary.Summary).countColumns()...)
+	return append(row, childCounts, formatter.FormatTimeNs(job.SubmitTime))
+}
+
+// Datacenters returns the sorted datacenters jobs run in
+func (jobs Jobs) Datacenters() []string {
+	seen := make(map[string]bool)
+	var datacenters []string
+	for _, job := range jobs {
+		for _, datacenter := range job.Datacenters {
+			if !seen[datacenter] {
+				seen[datacenter] = true
+				datacenters = append(datacenters, datacenter)
+			}
+		}
+	}
+	sort.Strings(datacenters)
+	return datacenters
 }
 
 func toJobsKey(jobResponseEntry jobResponseEntry) string {
-	return jobResponseEntry.ID
+	return strings.Join([]string{jobResponseEntry.ID, jobResponseEntry.Namespace, jobResponseEntry.ParentID}, " ")
+}
+
+func toJobGroupKey(jobID, namespace string) string {
+	return jobID + " " + namespace
+}
+
+func JobIDAndNamespaceFromKey(key string) (string, string) {
+	split := strings.SplitN(key, " ", 3)
+	return split[0], split[1]
+}
+
+// JobGroupFromKey returns the key of the tree group a job row belongs to, i.e. its parent's if it has one
+func JobGroupFromKey(key string) string {
+	split := strings.SplitN(key, " ", 3)
+	if split[2] != "" {
+		return toJobGroupKey(split[2], split[1])
+	}
+	return toJobGroupKey(split[0], split[1])
 }
 
-func JobIDFromKey(key string) string {
-	return key
+// JobKeyFromGroup returns the key of the row at the top of a tree group
+func JobKeyFromGroup(group string) string {
+	return group + " "
 }
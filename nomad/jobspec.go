@@ -1,17 +1,22 @@
 package nomad
 
 import (
-	"fmt"
+	"context"
 	tea "github.com/charmbracelet/bubbletea"
 	"wander/components/page"
 	"wander/formatter"
 	"wander/message"
 )
 
-func FetchJobSpec(url, token, jobID string) tea.Cmd {
+// Job returns the json spec of a job
+// https://www.nomadproject.io/api-docs/jobs#read-job
+func (c *Client) Job(ctx context.Context, jobID, namespace string) ([]byte, error) {
+	return c.get(ctx, "/v1/job/"+jobID, namespaceParams(namespace))
+}
+
+func FetchJobSpec(ctx context.Context, client *Client, jobID, namespace string) tea.Cmd {
 	return func() tea.Msg {
-		fullPath := fmt.Sprintf("%s%s%s", url, "/v1/job/", jobID)
-		body, err := get(fullPath, token, nil)
+		body, err := client.Job(ctx, jobID, namespace)
 		if err != nil {
 			return message.ErrMsg{Err: err}
 		}
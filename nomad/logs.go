@@ -1,9 +1,12 @@
 package nomad
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	tea "github.com/charmbracelet/bubbletea"
+	"strconv"
 	"strings"
+	"time"
 	"wander/components/page"
 	"wander/formatter"
 	"wander/message"
@@ -14,6 +17,7 @@ type LogType int8
 const (
 	StdOut LogType = iota
 	StdErr
+	StdOutAndErr
 )
 
 func (p LogType) String() string {
@@ -22,6 +26,8 @@ func (p LogType) String() string {
 		return "Stdout Logs"
 	case StdErr:
 		return "Stderr Logs"
+	case StdOutAndErr:
+		return "Stdout & Stderr Logs, by Line Timestamp"
 	}
 	return "Unknown"
 }
@@ -32,49 +38,340 @@ func (p LogType) ShortString() string {
 		return "stdout"
 	case StdErr:
 		return "stderr"
+	case StdOutAndErr:
+		return "stdout+stderr"
 	}
 	return "unknown"
 }
 
-func FetchLogs(url, token, allocID, taskName string, logType LogType) tea.Cmd {
+// MarshalText encodes log types by their short names, e.g. in json output
+func (p LogType) MarshalText() ([]byte, error) {
+	return []byte(p.ShortString()), nil
+}
+
+// logLine is a single line of log output and the stream it came from
+type logLine struct {
+	Text    string  `json:"text"`
+	LogType LogType `json:"stream"`
+}
+
+// Logs are the loaded lines of a task's logs. Logs are loaded backwards in chunks from the end of each stream.
+// loadedBytes tracks how far back from the end of each stream the first chunk reaches, and start tracks the offset
+// from the start of each stream that the lines begin at, once it's known.
+type Logs struct {
+	LogType      LogType
+	ReachedStart bool
+	lines        []logLine
+	loadedBytes  map[LogType]int
+	start        map[LogType]int
+	reachedStart map[LogType]bool
+	// generation identifies the load of the logs that earlier lines are added to
+	generation int
+}
+
+// EarlierLogsErrorMsg reports a failure to load earlier logs, leaving those already loaded shown
+type EarlierLogsErrorMsg struct {
+	Err        error
+	Generation int
+}
+
+// LogsLoadedMsg has the loaded logs. Generation identifies the load they're from, so results from before logs are
+// reloaded can be told apart.
+type LogsLoadedMsg struct {
+	Logs        Logs
+	Generation  int
+	TableHeader []string
+	AllPageData []page.Row
+	// Earlier is true if earlier logs were prepended to existing ones
+	Earlier bool
+	// PrependedRows is the number of rows at the start of AllPageData that were newly loaded
+	PrependedRows int
+}
+
+// FetchLogs loads the last tailBytes bytes of a task's logs, as the load identified by generation
+func FetchLogs(ctx context.Context, client *Client, allocID, taskName string, logType LogType, tailBytes, generation int) tea.Cmd {
 	return func() tea.Msg {
-		params := map[string]string{
-			"task":   taskName,
-			"type":   logType.ShortString(),
-			"origin": "end",
-			"offset": "1000000",
-			"plain":  "true",
-		}
-		fullPath := fmt.Sprintf("%s%s%s", url, "/v1/client/fs/logs/", allocID)
-		body, err := get(fullPath, token, params)
+		logs := newLogs(logType)
+		logs.generation = generation
+		newLines, err := logs.loadEarlier(ctx, client, allocID, taskName, tailBytes)
 		if err != nil {
 			return message.ErrMsg{Err: err}
 		}
+		logs.lines = newLines
+
+		tableHeader, allPageData := logsAsTable(logs.lines, logType)
+		return LogsLoadedMsg{Logs: logs, Generation: generation, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+// LogsTable returns the last tailBytes bytes of a task's logs, one row per non-empty line
+func LogsTable(ctx context.Context, client *Client, allocID, taskName string, logType LogType, tailBytes int) (Table, error) {
+	logs := newLogs(logType)
+	lines, err := logs.loadEarlier(ctx, client, allocID, taskName, tailBytes)
+	if err != nil {
+		return Table{}, err
+	}
+
+	var rows [][]string
+	nonEmpty := make([]logLine, 0, len(lines))
+	for _, line := range lines {
+		if stripped := strings.TrimSpace(line.Text); stripped != "" {
+			rows = append(rows, []string{stripped})
+			nonEmpty = append(nonEmpty, line)
+		}
+	}
+	return Table{Columns: []string{logType.String()}, Rows: rows, Objects: nonEmpty}, nil
+}
+
+// FetchEarlierLogs loads up to chunkBytes bytes of each stream preceding the already loaded logs
+func FetchEarlierLogs(ctx context.Context, client *Client, allocID, taskName string, logs Logs, chunkBytes int) tea.Cmd {
+	return func() tea.Msg {
+		logs = logs.copy()
+		newLines, err := logs.loadEarlier(ctx, client, allocID, taskName, chunkBytes)
+		if err != nil {
+			return EarlierLogsErrorMsg{Err: err, Generation: logs.generation}
+		}
+		logs.lines = append(newLines, logs.lines...)
 
-		logRows := strings.Split(string(body), "\n")
+		tableHeader, allPageData := logsAsTable(logs.lines, logs.LogType)
+		prependedRows := 0
+		for _, line := range newLines {
+			if strings.TrimSpace(line.Text) != "" {
+				prependedRows++
+			}
+		}
+		return LogsLoadedMsg{
+			Logs:          logs,
+			Generation:    logs.generation,
+			TableHeader:   tableHeader,
+			AllPageData:   allPageData,
+			Earlier:       true,
+			PrependedRows: prependedRows,
+		}
+	}
+}
 
-		tableHeader, allPageData := logsAsTable(logRows, logType)
-		return PageLoadedMsg{Page: LogsPage, TableHeader: tableHeader, AllPageData: allPageData}
+func newLogs(logType LogType) Logs {
+	return Logs{LogType: logType, loadedBytes: make(map[LogType]int), start: make(map[LogType]int), reachedStart: make(map[LogType]bool)}
+}
+
+func (l Logs) copy() Logs {
+	copied := newLogs(l.LogType)
+	for logType, n := range l.loadedBytes {
+		copied.loadedBytes[logType] = n
+	}
+	for logType, n := range l.start {
+		copied.start[logType] = n
 	}
+	for logType, reached := range l.reachedStart {
+		copied.reachedStart[logType] = reached
+	}
+	copied.ReachedStart, copied.lines, copied.generation = l.ReachedStart, l.lines, l.generation
+	return copied
 }
 
-func logsAsTable(logs []string, logType LogType) ([]string, []page.Row) {
+// loadEarlier fetches the chunkBytes bytes before the loaded logs of each stream, returning their lines
+func (l *Logs) loadEarlier(ctx context.Context, client *Client, allocID, taskName string, chunkBytes int) ([]logLine, error) {
+	logTypes := []LogType{l.LogType}
+	if l.LogType == StdOutAndErr {
+		logTypes = []LogType{StdOut, StdErr}
+	}
+
+	var chunks [][]logLine
+	for _, logType := range logTypes {
+		if l.reachedStart[logType] {
+			chunks = append(chunks, nil)
+			continue
+		}
+		var lines []logLine
+		var err error
+		if _, loaded := l.loadedBytes[logType]; !loaded {
+			lines, err = l.loadLast(ctx, client, allocID, taskName, logType, chunkBytes)
+		} else {
+			lines, err = l.loadBefore(ctx, client, allocID, taskName, logType, chunkBytes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, lines)
+	}
+
+	l.ReachedStart = true
+	for _, logType := range logTypes {
+		l.ReachedStart = l.ReachedStart && l.reachedStart[logType]
+	}
+
+	if len(chunks) == 2 {
+		return interleaveLogLines(chunks[0], chunks[1]), nil
+	}
+	return chunks[0], nil
+}
+
+// loadLast fetches the last chunkBytes bytes of a log stream
+func (l *Logs) loadLast(ctx context.Context, client *Client, allocID, taskName string, logType LogType, chunkBytes int) ([]logLine, error) {
+	body, err := client.TaskLogs(ctx, allocID, taskName, logType, chunkBytes)
+	if err != nil {
+		return nil, err
+	}
+	reachedStart := len(body) < chunkBytes
+	chunk := body
+	if !reachedStart {
+		chunk = trimPartialLine(chunk)
+	}
+	l.loadedBytes[logType], l.reachedStart[logType] = len(chunk), reachedStart
+	return splitLogLines(chunk, logType), nil
+}
+
+// loadBefore fetches up to chunkBytes bytes of a log stream before the loaded lines, reading from where they start so
+// only the new bytes are downloaded. Where they start is worked out from the stream's size the first time. If the log
+// grows in the meantime, the chunk may overlap slightly with what was already loaded.
+func (l *Logs) loadBefore(ctx context.Context, client *Client, allocID, taskName string, logType LogType, chunkBytes int) ([]logLine, error) {
+	start, known := l.start[logType]
+	if !known {
+		size, err := client.TaskLogsSize(ctx, allocID, taskName, logType)
+		if err != nil {
+			return nil, err
+		}
+		start = max(0, size-l.loadedBytes[logType])
+	}
+
+	from := max(0, start-chunkBytes)
+	reachedStart := from == 0
+	if !reachedStart {
+		// include the byte before the chunk, so a chunk starting at the start of a line is kept whole
+		from--
+	}
+	chunk, err := client.TaskLogsRange(ctx, allocID, taskName, logType, from, start-from)
+	if err != nil {
+		return nil, err
+	}
+	if !reachedStart {
+		trimmed := trimPartialLine(chunk)
+		from += len(chunk) - len(trimmed)
+		chunk = trimmed
+	}
+	l.start[logType], l.reachedStart[logType] = from, reachedStart
+	l.loadedBytes[logType] += len(chunk)
+	return splitLogLines(chunk, logType), nil
+}
+
+// trimPartialLine drops the start of chunk up to its first newline, as a chunk that doesn't start at the start of
+// a stream likely starts partway through a line, which is left to be loaded whole with the next chunk. Lines longer
+// than a chunk are kept in pieces, so loading always makes progress.
+func trimPartialLine(chunk []byte) []byte {
+	if firstNewline := bytes.IndexByte(chunk, '\n'); firstNewline >= 0 && firstNewline < len(chunk)-1 {
+		return chunk[firstNewline+1:]
+	}
+	return chunk
+}
+
+func splitLogLines(chunk []byte, logType LogType) []logLine {
+	var logLines []logLine
+	for _, row := range strings.Split(string(chunk), "\n") {
+		logLines = append(logLines, logLine{Text: row, LogType: logType})
+	}
+	return logLines
+}
+
+// TaskLogs reads a task's log stream from offset bytes before its end
+// https://www.nomadproject.io/api-docs/client#stream-logs
+func (c *Client) TaskLogs(ctx context.Context, allocID, taskName string, logType LogType, offset int) ([]byte, error) {
+	params := map[string]string{
+		"task":   taskName,
+		"type":   logType.ShortString(),
+		"origin": "end",
+		"offset": strconv.Itoa(offset),
+		"plain":  "true",
+	}
+	return c.get(ctx, "/v1/client/fs/logs/"+allocID, params)
+}
+
+// TaskLogsRange reads length bytes of a task's log stream from offset bytes after its start, closing the stream
+// rather than reading the rest of it
+// https://www.nomadproject.io/api-docs/client#stream-logs
+func (c *Client) TaskLogsRange(ctx context.Context, allocID, taskName string, logType LogType, offset, length int) ([]byte, error) {
+	if length <= 0 {
+		return nil, nil
+	}
+	params := map[string]string{
+		"task":   taskName,
+		"type":   logType.ShortString(),
+		"origin": "start",
+		"offset": strconv.Itoa(offset),
+		"plain":  "true",
+	}
+	return c.getLimited(ctx, "/v1/client/fs/logs/"+allocID, params, length)
+}
+
+// TaskLogsSize returns the size of a task's log stream, which Nomad rotates across files named like web.stdout.0 in
+// the allocation's log directory
+func (c *Client) TaskLogsSize(ctx context.Context, allocID, taskName string, logType LogType) (int, error) {
+	files, err := c.AllocFiles(ctx, allocID, "/alloc/logs")
+	if err != nil {
+		return 0, err
+	}
+	prefix := taskName + "." + logType.ShortString() + "."
+	size := 0
+	for _, file := range files {
+		if index := strings.TrimPrefix(file.Name, prefix); index != file.Name && !file.IsDir {
+			if _, err := strconv.Atoi(index); err == nil {
+				size += int(file.Size)
+			}
+		}
+	}
+	return size, nil
+}
+
+// interleaveLogLines merges stdout and stderr lines into a single ordered slice. Plain logs carry no timestamps of
+// their own, so lines are ordered by any timestamp they start with. Lines without one take the time of the previous
+// line in the same stream, or sort first if there isn't one, so each stream keeps its original order. Logs without
+// timestamps end up as all of stdout, then all of stderr. Earlier chunks are only interleaved with each other, and
+// shown above the lines already loaded.
+func interleaveLogLines(stdOutLines, stdErrLines []logLine) []logLine {
+	stdOutTimes, stdErrTimes := logLineTimes(stdOutLines), logLineTimes(stdErrLines)
+
+	var interleaved []logLine
+	outIdx, errIdx := 0, 0
+	for outIdx < len(stdOutLines) || errIdx < len(stdErrLines) {
+		takeStdErr := outIdx >= len(stdOutLines) ||
+			(errIdx < len(stdErrLines) && stdErrTimes[errIdx].Before(stdOutTimes[outIdx]))
+		if takeStdErr {
+			interleaved = append(interleaved, stdErrLines[errIdx])
+			errIdx++
+		} else {
+			interleaved = append(interleaved, stdOutLines[outIdx])
+			outIdx++
+		}
+	}
+	return interleaved
+}
+
+func logLineTimes(logLines []logLine) []time.Time {
+	var times []time.Time
+	var last time.Time
+	for _, line := range logLines {
+		if t, ok := formatter.ParseLogLineTime(line.Text); ok {
+			last = t
+		}
+		times = append(times, last)
+	}
+	return times
+}
+
+func logsAsTable(logs []logLine, logType LogType) ([]string, []page.Row) {
 	var logRows [][]string
 	var keys []string
+	var objects []interface{}
 	for _, row := range logs {
-		if stripped := strings.TrimSpace(row); stripped != "" {
+		if stripped := strings.TrimSpace(row.Text); stripped != "" {
 			logRows = append(logRows, []string{stripped})
+			keys = append(keys, row.LogType.ShortString())
+			objects = append(objects, row)
 		}
-		keys = append(keys, "")
 	}
 
 	columns := []string{logType.String()}
-	table := formatter.GetRenderedTableAsString(columns, logRows)
-
-	var rows []page.Row
-	for idx, row := range table.ContentRows {
-		rows = append(rows, page.Row{Key: keys[idx], Row: row})
-	}
+	tableHeader, rows := tableAsRows(columns, logRows, keys, objects)
 
-	return table.HeaderRows, rows
+	return tableHeader, rows
 }
@@ -0,0 +1,83 @@
+package nomad_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"wander/nomad"
+	"wander/nomad/nomadtest"
+)
+
+const logsAllocID = "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31"
+
+func rowTexts(msg nomad.LogsLoadedMsg) []string {
+	var texts []string
+	for _, row := range msg.AllPageData {
+		texts = append(texts, row.Row)
+	}
+	return texts
+}
+
+// loading earlier logs in small chunks should end up with the same lines as loading them all at once
+func TestFetchEarlierLogs(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	for _, logType := range []nomad.LogType{nomad.StdOut, nomad.StdOutAndErr} {
+		all := nomad.FetchLogs(ctx, client, logsAllocID, "nginx", logType, 1<<20, 0)().(nomad.LogsLoadedMsg)
+		if !all.Logs.ReachedStart {
+			t.Fatalf("%s: expected all logs to be loaded", logType)
+		}
+
+		loaded := nomad.FetchLogs(ctx, client, logsAllocID, "nginx", logType, 40, 0)().(nomad.LogsLoadedMsg)
+		for chunks := 0; !loaded.Logs.ReachedStart; chunks++ {
+			if chunks > 10 {
+				t.Fatalf("%s: didn't reach the start of the logs", logType)
+			}
+			msg := nomad.FetchEarlierLogs(ctx, client, logsAllocID, "nginx", loaded.Logs, 40)()
+			if loaded, _ = msg.(nomad.LogsLoadedMsg); !loaded.Earlier {
+				t.Fatalf("%s: got %#v", logType, msg)
+			}
+		}
+		if actual, expected := rowTexts(loaded), rowTexts(all); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: got %q, expected %q", logType, actual, expected)
+		}
+	}
+}
+
+func TestFetchEarlierLogsError(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	loaded := nomad.FetchLogs(ctx, client, logsAllocID, "nginx", nomad.StdOut, 40, 0)().(nomad.LogsLoadedMsg)
+	server.Fail("/v1/client/fs/ls/"+logsAllocID, http.StatusInternalServerError, "client unreachable", 0)
+	msg := nomad.FetchEarlierLogs(ctx, client, logsAllocID, "nginx", loaded.Logs, 40)()
+	if _, ok := msg.(nomad.EarlierLogsErrorMsg); !ok {
+		t.Errorf("got %#v, expected an EarlierLogsErrorMsg", msg)
+	}
+}
+
+// plain logs without timestamps can't be interleaved, so each stream is shown whole and in order
+func TestFetchLogsWithoutTimestamps(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+
+	msg := nomad.FetchLogs(context.Background(), server.Client(), logsAllocID, "worker", nomad.StdOutAndErr, 1<<20, 0)()
+	loaded, ok := msg.(nomad.LogsLoadedMsg)
+	if !ok {
+		t.Fatalf("got %#v", msg)
+	}
+	var actual []string
+	for _, row := range loaded.AllPageData {
+		actual = append(actual, row.Key+" "+row.Data.Cells[0])
+	}
+	expected := []string{"stdout starting worker", "stdout processing queue", "stderr retrying connection"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %q, expected %q", actual, expected)
+	}
+}
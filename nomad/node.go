@@ -0,0 +1,281 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// nodeResponse is returned from GET /v1/node/:node_id
+// https://www.nomadproject.io/api-docs/nodes#read-node
+type nodeResponse struct {
+	ID                    string            `json:"ID"`
+	Name                  string            `json:"Name"`
+	Address               string            `json:"HTTPAddr"`
+	Datacenter            string            `json:"Datacenter"`
+	NodeClass             string            `json:"NodeClass"`
+	Drain                 bool              `json:"Drain"`
+	SchedulingEligibility string            `json:"SchedulingEligibility"`
+	Status                string            `json:"Status"`
+	StatusDescription     string            `json:"StatusDescription"`
+	Attributes            map[string]string `json:"Attributes"`
+	DrainStrategy         *struct {
+		ForceDeadline time.Time `json:"ForceDeadline"`
+	} `json:"DrainStrategy"`
+	Drivers map[string]struct {
+		Detected          bool      `json:"Detected"`
+		Healthy           bool      `json:"Healthy"`
+		HealthDescription string    `json:"HealthDescription"`
+		UpdateTime        time.Time `json:"UpdateTime"`
+	} `json:"Drivers"`
+	HostVolumes map[string]struct {
+		Path     string `json:"Path"`
+		ReadOnly bool   `json:"ReadOnly"`
+	} `json:"HostVolumes"`
+	Events []struct {
+		Message   string    `json:"Message"`
+		Subsystem string    `json:"Subsystem"`
+		Timestamp time.Time `json:"Timestamp"`
+	} `json:"Events"`
+	NodeResources struct {
+		Cpu struct {
+			CpuShares int `json:"CpuShares"`
+		} `json:"Cpu"`
+		Memory struct {
+			MemoryMB int `json:"MemoryMB"`
+		} `json:"Memory"`
+		Disk struct {
+			DiskMB int `json:"DiskMB"`
+		} `json:"Disk"`
+	} `json:"NodeResources"`
+}
+
+// nodeAllocationResponseEntry is the part of GET /v1/node/:node_id/allocations needed to sum allocated resources
+// https://www.nomadproject.io/api-docs/nodes#list-node-allocations
+type nodeAllocationResponseEntry struct {
+	ClientStatus       string `json:"ClientStatus"`
+	AllocatedResources struct {
+		Tasks map[string]struct {
+			Cpu struct {
+				CpuShares int `json:"CpuShares"`
+			} `json:"Cpu"`
+			Memory struct {
+				MemoryMB int `json:"MemoryMB"`
+			} `json:"Memory"`
+		} `json:"Tasks"`
+		Shared struct {
+			DiskMB int `json:"DiskMB"`
+		} `json:"Shared"`
+	} `json:"AllocatedResources"`
+}
+
+type NodeLoadedMsg struct {
+	NodeID          string
+	Drain, Eligible bool
+	AllPageData     []page.Row
+}
+
+// Node reads a client node
+// https://www.nomadproject.io/api-docs/nodes#read-node
+func (c *Client) Node(ctx context.Context, nodeID string) (nodeResponse, error) {
+	var node nodeResponse
+	err := c.getJSON(ctx, "/v1/node/"+nodeID, nil, &node)
+	return node, err
+}
+
+// NodeAllocations lists the allocations placed on a client node
+// https://www.nomadproject.io/api-docs/nodes#list-node-allocations
+func (c *Client) NodeAllocations(ctx context.Context, nodeID string) ([]nodeAllocationResponseEntry, error) {
+	var allocations []nodeAllocationResponseEntry
+	err := c.getJSON(ctx, "/v1/node/"+nodeID+"/allocations", nil, &allocations)
+	return allocations, err
+}
+
+// UpdateNodeDrain sets the drain spec of a node, with a nil spec disabling drain and marking the node eligible
+// again, as `nomad node drain -disable` does
+// https://www.nomadproject.io/api-docs/nodes#drain-node
+func (c *Client) UpdateNodeDrain(ctx context.Context, nodeID string, drainSpec map[string]interface{}) error {
+	_, err := c.post(ctx, "/v1/node/"+nodeID+"/drain", nil, map[string]interface{}{"DrainSpec": drainSpec, "MarkEligible": drainSpec == nil})
+	return err
+}
+
+// UpdateNodeEligibility sets the scheduling eligibility of a node, either "eligible" or "ineligible"
+// https://www.nomadproject.io/api-docs/nodes#toggle-node-eligibility
+func (c *Client) UpdateNodeEligibility(ctx context.Context, nodeID, eligibility string) error {
+	_, err := c.post(ctx, "/v1/node/"+nodeID+"/eligibility", nil, map[string]string{"Eligibility": eligibility})
+	return err
+}
+
+func FetchNode(ctx context.Context, client *Client, nodeID string) tea.Cmd {
+	return func() tea.Msg {
+		node, err := client.Node(ctx, nodeID)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		nodeAllocations, err := client.NodeAllocations(ctx, nodeID)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		return NodeLoadedMsg{
+			NodeID:      node.ID,
+			Drain:       node.Drain,
+			Eligible:    node.SchedulingEligibility == "eligible",
+			AllPageData: nodeAsSections(node, nodeAllocations),
+		}
+	}
+}
+
+func nodeAsSections(node nodeResponse, nodeAllocations []nodeAllocationResponseEntry) []page.Row {
+	var sections sectionsBuilder
+
+	drain := strconv.FormatBool(node.Drain)
+	if node.DrainStrategy != nil {
+		drain += fmt.Sprintf(" (deadline %s)", formatter.FormatTime(node.DrainStrategy.ForceDeadline))
+	}
+	sections.addTable("Node", []string{"Field", "Value"}, [][]string{
+		{"ID", node.ID},
+		{"Name", node.Name},
+		{"Address", node.Address},
+		{"Datacenter", node.Datacenter},
+		{"Class", node.NodeClass},
+		{"Status", fmt.Sprintf("%s %s", node.Status, node.StatusDescription)},
+		{"Eligibility", node.SchedulingEligibility},
+		{"Drain", drain},
+	})
+
+	var allocatedCpu, allocatedMemory, allocatedDisk int
+	for _, alloc := range nodeAllocations {
+		if alloc.ClientStatus != "running" && alloc.ClientStatus != "pending" {
+			continue
+		}
+		for _, task := range alloc.AllocatedResources.Tasks {
+			allocatedCpu += task.Cpu.CpuShares
+			allocatedMemory += task.Memory.MemoryMB
+		}
+		allocatedDisk += alloc.AllocatedResources.Shared.DiskMB
+	}
+	total := node.NodeResources
+	sections.addTable("Resources", []string{"Resource", "Allocated", "Total", "Used"}, [][]string{
+		{"CPU", fmt.Sprintf("%d MHz", allocatedCpu), fmt.Sprintf("%d MHz", total.Cpu.CpuShares), formatPercent(allocatedCpu, total.Cpu.CpuShares)},
+		{"Memory", fmt.Sprintf("%d MiB", allocatedMemory), fmt.Sprintf("%d MiB", total.Memory.MemoryMB), formatPercent(allocatedMemory, total.Memory.MemoryMB)},
+		{"Disk", fmt.Sprintf("%d MiB", allocatedDisk), fmt.Sprintf("%d MiB", total.Disk.DiskMB), formatPercent(allocatedDisk, total.Disk.DiskMB)},
+	})
+
+	var driverNames []string
+	for name := range node.Drivers {
+		driverNames = append(driverNames, name)
+	}
+	sort.Strings(driverNames)
+	var driverRows [][]string
+	for _, name := range driverNames {
+		driver := node.Drivers[name]
+		driverRows = append(driverRows, []string{
+			name,
+			strconv.FormatBool(driver.Detected),
+			strconv.FormatBool(driver.Healthy),
+			driver.HealthDescription,
+			formatter.FormatTime(driver.UpdateTime),
+		})
+	}
+	sections.addTable("Drivers", []string{"Driver", "Detected", "Healthy", "Description", "Updated"}, driverRows)
+
+	var volumeNames []string
+	for name := range node.HostVolumes {
+		volumeNames = append(volumeNames, name)
+	}
+	sort.Strings(volumeNames)
+	var volumeRows [][]string
+	for _, name := range volumeNames {
+		volume := node.HostVolumes[name]
+		volumeRows = append(volumeRows, []string{name, volume.Path, strconv.FormatBool(volume.ReadOnly)})
+	}
+	sections.addTable("Host Volumes", []string{"Name", "Path", "Read Only"}, volumeRows)
+
+	var eventRows [][]string
+	for idx := len(node.Events) - 1; idx >= 0; idx-- {
+		event := node.Events[idx]
+		eventRows = append(eventRows, []string{formatter.FormatTime(event.Timestamp), event.Subsystem, event.Message})
+	}
+	sections.addTable("Events (newest first)", []string{"Time", "Subsystem", "Message"}, eventRows)
+
+	var attributeNames []string
+	for name := range node.Attributes {
+		attributeNames = append(attributeNames, name)
+	}
+	sort.Strings(attributeNames)
+	var attributeRows [][]string
+	for _, name := range attributeNames {
+		attributeRows = append(attributeRows, []string{name, node.Attributes[name]})
+	}
+	sections.addTable("Attributes", []string{"Attribute", "Value"}, attributeRows)
+
+	return sections.rows
+}
+
+// SetNodeDrain enables drain on a node with the given deadline, or disables it if enable is false.
+// A deadline of 0 means no deadline, and a negative deadline forces the drain immediately.
+func SetNodeDrain(ctx context.Context, client *Client, nodeID string, enable bool, deadline time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		var drainSpec map[string]interface{}
+		if enable {
+			drainSpec = map[string]interface{}{"Deadline": deadline.Nanoseconds(), "IgnoreSystemJobs": false}
+		}
+		if err := client.UpdateNodeDrain(ctx, nodeID, drainSpec); err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+
+		if enable {
+			return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("Enabled drain on node %s", formatter.ShortAllocID(nodeID))}
+		}
+		return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("Disabled drain on node %s and marked it eligible", formatter.ShortAllocID(nodeID))}
+	}
+}
+
+// SetNodeEligibility marks a node as eligible or ineligible for scheduling
+func SetNodeEligibility(ctx context.Context, client *Client, nodeID string, eligible bool) tea.Cmd {
+	return func() tea.Msg {
+		eligibility := "ineligible"
+		if eligible {
+			eligibility = "eligible"
+		}
+		if err := client.UpdateNodeEligibility(ctx, nodeID, eligibility); err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+		return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("Marked node %s %s", formatter.ShortAllocID(nodeID), eligibility)}
+	}
+}
+
+// ParseDrainDeadline parses a drain deadline as entered by a user: a duration like "1h", "none" for no deadline, or
+// "force" to force the drain immediately
+func ParseDrainDeadline(s string) (time.Duration, error) {
+	switch s = strings.TrimSpace(s); s {
+	case "", "none":
+		return 0, nil
+	case "force":
+		return -1, nil
+	}
+	deadline, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if deadline <= 0 {
+		return 0, fmt.Errorf("deadline must be positive, 'none' or 'force'")
+	}
+	return deadline, nil
+}
+
+func formatPercent(numerator, denominator int) string {
+	if denominator <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(numerator)/float64(denominator)*100)
+}
@@ -0,0 +1,123 @@
+package nomad
+
+import (
+	"context"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// nodeResponseEntry is returned from GET /v1/nodes
+// https://www.nomadproject.io/api-docs/nodes#list-nodes
+type nodeResponseEntry struct {
+	ID                    string `json:"ID"`
+	Name                  string `json:"Name"`
+	Address               string `json:"Address"`
+	Datacenter            string `json:"Datacenter"`
+	NodeClass             string `json:"NodeClass"`
+	Version               string `json:"Version"`
+	Drain                 bool   `json:"Drain"`
+	SchedulingEligibility string `json:"SchedulingEligibility"`
+	Status                string `json:"Status"`
+	StatusDescription     string `json:"StatusDescription"`
+}
+
+// Nodes lists the client nodes
+// https://www.nomadproject.io/api-docs/nodes#list-nodes
+func (c *Client) Nodes(ctx context.Context) ([]nodeResponseEntry, error) {
+	var nodes []nodeResponseEntry
+	if err := c.getJSON(ctx, "/v1/nodes", nil, &nodes); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, node := range nodes {
+		c.nodeDatacenters[node.ID] = node.Datacenter
+	}
+	return nodes, nil
+}
+
+// NodeDatacenters returns the datacenter of each node in nodeIDs. A node's datacenter doesn't change, so they're
+// remembered from listing nodes, which is only done again when a node isn't known yet.
+func (c *Client) NodeDatacenters(ctx context.Context, nodeIDs []string) (map[string]string, error) {
+	datacenters, missing := c.knownNodeDatacenters(nodeIDs)
+	if missing {
+		if _, err := c.Nodes(ctx); err != nil {
+			return nil, err
+		}
+		datacenters, _ = c.knownNodeDatacenters(nodeIDs)
+	}
+	return datacenters, nil
+}
+
+func (c *Client) knownNodeDatacenters(nodeIDs []string) (map[string]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	datacenters := make(map[string]string)
+	missing := false
+	for _, nodeID := range nodeIDs {
+		datacenter, known := c.nodeDatacenters[nodeID]
+		missing = missing || !known
+		datacenters[nodeID] = datacenter
+	}
+	return datacenters, missing
+}
+
+func FetchNodes(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		nodeResponse, err := client.Nodes(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(nodeResponse, func(x, y int) bool {
+			firstNode := nodeResponse[x]
+			secondNode := nodeResponse[y]
+			if firstNode.Name == secondNode.Name {
+				return firstNode.ID < secondNode.ID
+			}
+			return firstNode.Name < secondNode.Name
+		})
+
+		tableHeader, allPageData := nodesAsTable(nodeResponse)
+		return PageLoadedMsg{Page: NodesPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+func nodesAsTable(nodes []nodeResponseEntry) ([]string, []page.Row) {
+	var nodeResponseRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range nodes {
+		nodeResponseRows = append(nodeResponseRows, []string{
+			formatter.ShortAllocID(row.ID),
+			row.Name,
+			row.Datacenter,
+			row.NodeClass,
+			row.Address,
+			row.Status,
+			row.SchedulingEligibility,
+			strconv.FormatBool(row.Drain),
+			row.Version,
+		})
+		keys = append(keys, toNodesKey(row))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"ID", "Name", "Datacenter", "Class", "Address", "Status", "Eligibility", "Drain", "Version"}
+	tableHeader, rows := tableAsRows(columns, nodeResponseRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func toNodesKey(nodeResponseEntry nodeResponseEntry) string {
+	return nodeResponseEntry.ID
+}
+
+func NodeIDFromKey(key string) string {
+	return key
+}
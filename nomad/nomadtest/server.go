@@ -0,0 +1,218 @@
+// Package nomadtest runs an in-process fake Nomad HTTP API serving fixtures, for testing wander without a cluster.
+package nomadtest
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"wander/nomad"
+)
+
+// URL is the address clients from Server.Client report, so output mentioning it doesn't depend on the test port
+const URL = "http://nomad.test"
+
+// Token is the ACL token the fake API accepts
+const Token = "test-token"
+
+//go:embed fixtures
+var fixtures embed.FS
+
+type failure struct {
+	status    int
+	body      string
+	remaining int
+}
+
+// Server is a fake Nomad API. GET requests are answered from the fixtures directory, which mirrors API paths, e.g.
+// GET /v1/job/web is answered with fixtures/job/web.json. Task logs are read from
+// fixtures/client/fs/logs/:alloc_id/:task.stdout and .stderr, and files from fixtures/client/fs/cat/:alloc_id/:path.
+// Missing fixtures are answered with a 404, and requests
+// without Token are forbidden, as Nomad does. Writes succeed without changing anything, and are recorded for Writes
+// and WriteBody.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures map[string]*failure
+	requests map[string]int
+	writes   []string
+	bodies   map[string][]byte
+}
+
+func NewServer() *Server {
+	s := &Server{failures: make(map[string]*failure), requests: make(map[string]int), bodies: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the server that reports URL as its address. Retries are quick so transient
+// failures don't slow tests down.
+func (s *Server) Client() *nomad.Client {
+	client := nomad.NewClient(URL, Token)
+	client.RetryBackoff = 0
+	client.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, s.Listener.Addr().String())
+		},
+	}}
+	return client
+}
+
+// Fail answers the next times requests to apiPath with status and body, or every request if times is 0
+func (s *Server) Fail(apiPath string, status int, body string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[apiPath] = &failure{status: status, body: body, remaining: times}
+}
+
+// Requests returns how many requests have been made to apiPath
+func (s *Server) Requests(apiPath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[apiPath]
+}
+
+// Writes returns the method and path of each successful write request in order, e.g. "DELETE /v1/job/web"
+func (s *Server) Writes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.writes...)
+}
+
+// WriteBody returns the body of the last successful write request to apiPath
+func (s *Server) WriteBody(apiPath string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies[apiPath]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	fail, failing := s.failures[r.URL.Path]
+	if failing && fail.remaining > 0 {
+		fail.remaining--
+		if fail.remaining == 0 {
+			delete(s.failures, r.URL.Path)
+		}
+	}
+	s.mu.Unlock()
+
+	if r.Header.Get("X-Nomad-Token") != Token {
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+	if failing {
+		http.Error(w, fail.body, fail.status)
+		return
+	}
+	if r.Method != http.MethodGet {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.writes = append(s.writes, r.Method+" "+r.URL.Path)
+		s.bodies[r.URL.Path] = body
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/v1/client/fs/logs/") {
+		s.serveLogs(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/v1/client/fs/cat/") {
+		s.serveFile(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/v1/client/fs/ls/") && r.URL.Query().Get("path") == "/alloc/logs" {
+		s.serveLogFiles(w, r)
+		return
+	}
+	if r.URL.Path == "/v1/acl/token/self" {
+		http.Error(w, "ACL support disabled", http.StatusBadRequest)
+		return
+	}
+
+	body, err := fs.ReadFile(fixtures, path.Join("fixtures", strings.TrimPrefix(r.URL.Path, "/v1/")+".json"))
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// serveLogs answers plain log requests, returning the stream from offset bytes after its start with origin=start, or
+// the last offset bytes of it otherwise
+func (s *Server) serveLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	allocID := strings.TrimPrefix(r.URL.Path, "/v1/client/fs/logs/")
+	logs, err := fs.ReadFile(fixtures, path.Join("fixtures/client/fs/logs", allocID, query.Get("task")+"."+query.Get("type")))
+	if err != nil {
+		http.Error(w, "unknown allocation or task", http.StatusNotFound)
+		return
+	}
+
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+	if query.Get("origin") == "start" {
+		if offset > len(logs) {
+			offset = len(logs)
+		}
+		logs = logs[offset:]
+	} else if offset < len(logs) {
+		logs = logs[len(logs)-offset:]
+	}
+	_, _ = w.Write(logs)
+}
+
+// serveFile answers requests for the contents of a file in an allocation
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	allocID := strings.TrimPrefix(r.URL.Path, "/v1/client/fs/cat/")
+	contents, err := fs.ReadFile(fixtures, path.Join("fixtures/client/fs/cat", allocID, r.URL.Query().Get("path")))
+	if err != nil {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(contents)
+}
+
+// serveLogFiles lists an allocation's log directory, with each task's log fixtures as the first file of its stream
+func (s *Server) serveLogFiles(w http.ResponseWriter, r *http.Request) {
+	allocID := strings.TrimPrefix(r.URL.Path, "/v1/client/fs/ls/")
+	entries, err := fs.ReadDir(fixtures, path.Join("fixtures/client/fs/logs", allocID))
+	if err != nil {
+		http.Error(w, "unknown allocation", http.StatusNotFound)
+		return
+	}
+
+	type file struct {
+		Name string
+		Size int64
+	}
+	var files []file
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		files = append(files, file{Name: entry.Name() + ".0", Size: info.Size()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(files)
+}
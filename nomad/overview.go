@@ -0,0 +1,212 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"strings"
+	"wander/formatter"
+	"wander/message"
+)
+
+const overviewRecentFailedEvals = 10
+
+// agentMembersResponse is returned from GET /v1/agent/members
+// https://www.nomadproject.io/api-docs/agent#list-members
+type agentMembersResponse struct {
+	Members []struct {
+		Name   string            `json:"Name"`
+		Addr   string            `json:"Addr"`
+		Port   int               `json:"Port"`
+		Status string            `json:"Status"`
+		Tags   map[string]string `json:"Tags"`
+	} `json:"Members"`
+}
+
+// evaluationResponseEntry is returned from GET /v1/evaluations
+// https://www.nomadproject.io/api-docs/evaluations#list-evaluations
+type evaluationResponseEntry struct {
+	ID                string `json:"ID"`
+	Namespace         string `json:"Namespace"`
+	JobID             string `json:"JobID"`
+	Status            string `json:"Status"`
+	StatusDescription string `json:"StatusDescription"`
+	TriggeredBy       string `json:"TriggeredBy"`
+	ModifyTime        int64  `json:"ModifyTime"`
+}
+
+// Leader returns the address of the cluster leader
+// https://www.nomadproject.io/api-docs/status#read-leader
+func (c *Client) Leader(ctx context.Context) (string, error) {
+	var leader string
+	err := c.getJSON(ctx, "/v1/status/leader", nil, &leader)
+	return leader, err
+}
+
+// AgentMembers lists the servers known to the agent
+// https://www.nomadproject.io/api-docs/agent#list-members
+func (c *Client) AgentMembers(ctx context.Context) (agentMembersResponse, error) {
+	var members agentMembersResponse
+	err := c.getJSON(ctx, "/v1/agent/members", nil, &members)
+	return members, err
+}
+
+// Evaluations lists the evaluations in every namespace
+// https://www.nomadproject.io/api-docs/evaluations#list-evaluations
+func (c *Client) Evaluations(ctx context.Context) ([]evaluationResponseEntry, error) {
+	var evaluations []evaluationResponseEntry
+	err := c.getJSON(ctx, "/v1/evaluations", map[string]string{"namespace": "*"}, &evaluations)
+	return evaluations, err
+}
+
+func FetchOverview(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		leader, err := client.Leader(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		members, err := client.AgentMembers(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		nodes, err := client.Nodes(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		jobs, err := client.Jobs(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		allocations, err := client.Allocations(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		evaluations, err := client.Evaluations(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var sections sectionsBuilder
+
+		var memberRows [][]string
+		for _, member := range members.Members {
+			address := fmt.Sprintf("%s:%d", member.Addr, member.Port)
+			isLeader := ""
+			if strings.Split(leader, ":")[0] == member.Addr {
+				isLeader = "leader"
+			}
+			memberRows = append(memberRows, []string{member.Name, address, member.Status, member.Tags["region"], member.Tags["dc"], isLeader})
+		}
+		sort.Slice(memberRows, func(x, y int) bool {
+			return memberRows[x][0] < memberRows[y][0]
+		})
+		sections.addTable(fmt.Sprintf("Servers (leader %s)", leader), []string{"Name", "Address", "Status", "Region", "Datacenter", "Leader"}, memberRows)
+
+		nodeStatusCounts, nodeEligibilityCounts := make(map[string]int), make(map[string]int)
+		for _, node := range nodes {
+			nodeStatusCounts[node.Status]++
+			nodeEligibilityCounts[node.SchedulingEligibility]++
+		}
+		addCountsTable(&sections, "Nodes by Status", "Status", nodeStatusCounts, NodesPage)
+		addCountsTable(&sections, "Nodes by Eligibility", "Eligibility", nodeEligibilityCounts, NodesPage)
+
+		jobStatusCounts, jobTypeCounts := make(map[string]int), make(map[string]int)
+		for _, job := range jobs {
+			jobStatusCounts[job.Status]++
+			jobTypeCounts[job.Type]++
+		}
+		addCountsTable(&sections, "Jobs by Status", "Status", jobStatusCounts, JobsPage)
+		addCountsTable(&sections, "Jobs by Type", "Type", jobTypeCounts, JobsPage)
+
+		allocationCounts := make(map[string]int)
+		for _, status := range []string{"running", "pending", "failed"} {
+			allocationCounts[status] = 0
+		}
+		for _, allocation := range allocations {
+			if _, counted := allocationCounts[allocation.ClientStatus]; counted {
+				allocationCounts[allocation.ClientStatus]++
+			}
+		}
+		addCountsTable(&sections, "Allocations", "Status", allocationCounts, AllocationsPage)
+
+		var failedEvaluations []evaluationResponseEntry
+		for _, evaluation := range evaluations {
+			if evaluation.Status == "failed" {
+				failedEvaluations = append(failedEvaluations, evaluation)
+			}
+		}
+		sort.Slice(failedEvaluations, func(x, y int) bool {
+			return failedEvaluations[x].ModifyTime > failedEvaluations[y].ModifyTime
+		})
+		var evaluationRows [][]string
+		var evaluationKeys []string
+		for idx, evaluation := range failedEvaluations {
+			if idx >= overviewRecentFailedEvals {
+				break
+			}
+			evaluationRows = append(evaluationRows, []string{
+				formatter.ShortAllocID(evaluation.ID),
+				evaluation.JobID,
+				evaluation.Namespace,
+				evaluation.TriggeredBy,
+				evaluation.StatusDescription,
+				formatter.FormatTimeNs(evaluation.ModifyTime),
+			})
+			evaluationKeys = append(evaluationKeys, toOverviewLinkKey(AllocationsPage, evaluation.JobID, evaluation.Namespace, ""))
+		}
+		sections.addTableWithKeys(
+			"Recent Failed Evaluations",
+			[]string{"Eval ID", "Job", "Namespace", "Triggered By", "Description", "Modified"},
+			evaluationRows,
+			evaluationKeys,
+		)
+
+		return PageLoadedMsg{
+			Page:        OverviewPage,
+			TableHeader: []string{},
+			AllPageData: sections.rows,
+		}
+	}
+}
+
+// addCountsTable adds a table of counts by category, e.g. status, each row linking to linkPage filtered by that category
+func addCountsTable(sections *sectionsBuilder, title, category string, counts map[string]int, linkPage Page) {
+	var statuses []string
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	var rows [][]string
+	var keys []string
+	for _, status := range statuses {
+		rows = append(rows, []string{status, strconv.Itoa(counts[status])})
+		keys = append(keys, toOverviewLinkKey(linkPage, "", "", status))
+	}
+	sections.addTableWithKeys(title, []string{category, "Count"}, rows, keys)
+}
+
+func toOverviewLinkKey(linkPage Page, jobID, namespace, filter string) string {
+	return strings.Join([]string{"link", strconv.Itoa(int(linkPage)), jobID, namespace, filter}, " ")
+}
+
+// OverviewLinkFromKey returns the page an overview row links to, the job and its namespace to show if any, and the
+// filter to apply. Returns false if the row doesn't link anywhere.
+func OverviewLinkFromKey(key string) (Page, string, string, string, bool) {
+	split := strings.SplitN(key, " ", 5)
+	if len(split) != 5 || split[0] != "link" {
+		return Unset, "", "", "", false
+	}
+	linkPage, err := strconv.Atoi(split[1])
+	if err != nil {
+		return Unset, "", "", "", false
+	}
+	return Page(linkPage), split[2], split[3], split[4], true
+}
@@ -3,7 +3,9 @@ package nomad
 import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
 	"strings"
+	"unicode/utf8"
 	"wander/components/page"
 	"wander/components/viewport"
 	"wander/formatter"
@@ -21,6 +23,23 @@ const (
 	AllocSpecPage
 	LogsPage
 	LoglinePage
+	AllocFilesPage
+	AllocFilePage
+	TemplatesPage
+	StatsPage
+	NodesPage
+	NodePage
+	ServicesPage
+	ServicePage
+	VolumesPage
+	VolumePage
+	PluginsPage
+	PluginPage
+	TokenPage
+	OverviewPage
+	TaskGroupsPage
+	ScalePage
+	RegionsPage
 )
 
 func (p Page) Loads() bool {
@@ -49,6 +68,40 @@ func (p Page) String() string {
 		return "logs"
 	case LoglinePage:
 		return "log"
+	case AllocFilesPage:
+		return "files"
+	case AllocFilePage:
+		return "file"
+	case TemplatesPage:
+		return "templates"
+	case StatsPage:
+		return "stats"
+	case NodesPage:
+		return "nodes"
+	case NodePage:
+		return "node"
+	case ServicesPage:
+		return "services"
+	case ServicePage:
+		return "service"
+	case VolumesPage:
+		return "volumes"
+	case VolumePage:
+		return "volume"
+	case PluginsPage:
+		return "plugins"
+	case PluginPage:
+		return "plugin"
+	case TokenPage:
+		return "token"
+	case OverviewPage:
+		return "overview"
+	case TaskGroupsPage:
+		return "task groups"
+	case ScalePage:
+		return "scale"
+	case RegionsPage:
+		return "regions"
 	}
 	return "unknown"
 }
@@ -65,6 +118,18 @@ func (p Page) Forward() Page {
 		return LogsPage
 	case LogsPage:
 		return LoglinePage
+	case AllocFilesPage:
+		return AllocFilePage
+	case NodesPage:
+		return NodePage
+	case ServicesPage:
+		return ServicePage
+	case ServicePage:
+		return LogsPage
+	case VolumesPage:
+		return VolumePage
+	case PluginsPage:
+		return PluginPage
 	}
 	return p
 }
@@ -81,48 +146,234 @@ func (p Page) Backward() Page {
 		return AllocationsPage
 	case LoglinePage:
 		return LogsPage
+	case AllocFilesPage:
+		return AllocationsPage
+	case AllocFilePage:
+		return AllocFilesPage
+	case TemplatesPage:
+		return AllocationsPage
+	case StatsPage:
+		return AllocationsPage
+	case NodesPage:
+		return JobsPage
+	case NodePage:
+		return NodesPage
+	case ServicesPage:
+		return JobsPage
+	case ServicePage:
+		return ServicesPage
+	case VolumesPage:
+		return JobsPage
+	case VolumePage:
+		return VolumesPage
+	case PluginsPage:
+		return VolumesPage
+	case PluginPage:
+		return PluginsPage
+	case TokenPage:
+		return JobsPage
+	case OverviewPage:
+		return JobsPage
+	case TaskGroupsPage:
+		return JobsPage
+	case ScalePage:
+		return JobsPage
+	case RegionsPage:
+		return JobsPage
 	}
 	return p
 }
 
-func (p Page) GetFilterPrefix(jobID, taskName, allocID string) string {
+// PageContext identifies what a page is showing, e.g. the allocation for the logs page
+type PageContext struct {
+	JobID, TaskName, AllocID, FilePath, NodeID, ServiceName, VolumeID, PluginID, Datacenter string
+}
+
+func (p Page) GetFilterPrefix(c PageContext) string {
 	switch p {
 	case JobsPage:
+		if c.Datacenter != "" {
+			return fmt.Sprintf("Jobs in %s", style.Bold.Render(c.Datacenter))
+		}
 		return "Jobs"
 	case JobSpecPage:
-		return fmt.Sprintf("Job Spec for %s", style.Bold.Render(jobID))
+		return fmt.Sprintf("Job Spec for %s", style.Bold.Render(c.JobID))
 	case AllocationsPage:
-		return fmt.Sprintf("Allocations for %s", style.Bold.Render(jobID))
+		prefix := fmt.Sprintf("Allocations for %s", style.Bold.Render(c.JobID))
+		if c.JobID == "" {
+			prefix = "All Allocations"
+		}
+		if c.Datacenter != "" {
+			prefix += fmt.Sprintf(" in %s", style.Bold.Render(c.Datacenter))
+		}
+		return prefix
 	case AllocSpecPage:
-		return fmt.Sprintf("Allocation Spec for %s %s", style.Bold.Render(taskName), formatter.ShortAllocID(allocID))
+		return fmt.Sprintf("Allocation Spec for %s %s", style.Bold.Render(c.TaskName), formatter.ShortAllocID(c.AllocID))
 	case LogsPage:
-		return fmt.Sprintf("Logs for %s %s", style.Bold.Render(taskName), formatter.ShortAllocID(allocID))
+		return fmt.Sprintf("Logs for %s %s", style.Bold.Render(c.TaskName), formatter.ShortAllocID(c.AllocID))
 	case LoglinePage:
-		return fmt.Sprintf("Log Line for %s %s", style.Bold.Render(taskName), formatter.ShortAllocID(allocID))
+		return fmt.Sprintf("Log Line for %s %s", style.Bold.Render(c.TaskName), formatter.ShortAllocID(c.AllocID))
+	case AllocFilesPage:
+		return fmt.Sprintf("Files in %s for %s", style.Bold.Render(c.FilePath), formatter.ShortAllocID(c.AllocID))
+	case AllocFilePage:
+		return fmt.Sprintf("File %s for %s", style.Bold.Render(c.FilePath), formatter.ShortAllocID(c.AllocID))
+	case TemplatesPage:
+		return fmt.Sprintf("Templates & Env for %s %s", style.Bold.Render(c.TaskName), formatter.ShortAllocID(c.AllocID))
+	case StatsPage:
+		return fmt.Sprintf("Resource Usage for %s", formatter.ShortAllocID(c.AllocID))
+	case NodesPage:
+		return "Nodes"
+	case NodePage:
+		return fmt.Sprintf("Node %s", style.Bold.Render(formatter.ShortAllocID(c.NodeID)))
+	case ServicesPage:
+		return "Services"
+	case ServicePage:
+		return fmt.Sprintf("Instances of %s", style.Bold.Render(c.ServiceName))
+	case VolumesPage:
+		return "CSI Volumes"
+	case VolumePage:
+		return fmt.Sprintf("Volume %s", style.Bold.Render(c.VolumeID))
+	case PluginsPage:
+		return "CSI Plugins"
+	case PluginPage:
+		return fmt.Sprintf("Plugin %s", style.Bold.Render(c.PluginID))
+	case TokenPage:
+		return "ACL Token"
+	case OverviewPage:
+		return "Cluster Overview"
+	case TaskGroupsPage:
+		return fmt.Sprintf("Task Groups for %s", style.Bold.Render(c.JobID))
+	case ScalePage:
+		return fmt.Sprintf("Scale %s", style.Bold.Render(c.JobID))
+	case RegionsPage:
+		return "Regions"
 	default:
 		panic("page not found")
 	}
 }
 
+// CopiedName is what the copy key copies from the selected row of the page, or "" if it has nothing to copy
+func (p Page) CopiedName() string {
+	switch p {
+	case JobsPage:
+		return "job id"
+	case AllocationsPage, ServicePage:
+		return "alloc id"
+	case LogsPage, LoglinePage:
+		return "log line"
+	case NodesPage:
+		return "node id"
+	case ServicesPage:
+		return "service name"
+	case VolumesPage:
+		return "volume id"
+	case PluginsPage:
+		return "plugin id"
+	case AllocFilesPage:
+		return "file path"
+	case TaskGroupsPage:
+		return "task group"
+	case RegionsPage:
+		return "region"
+	}
+	return ""
+}
+
+// CopiedValue returns what the copy key copies from row, the full ID where the table only shows part of it
+func (p Page) CopiedValue(row page.Row) string {
+	switch p {
+	case JobsPage:
+		jobID, _ := JobIDAndNamespaceFromKey(row.Key)
+		return jobID
+	case AllocationsPage:
+		allocID, _ := AllocIDAndTaskNameFromKey(row.Key)
+		return allocID
+	case ServicePage:
+		allocID, _ := AllocIDAndJobIDFromKey(row.Key)
+		return allocID
+	case LogsPage:
+		if row.Data != nil && len(row.Data.Cells) > 0 {
+			return row.Data.Cells[0]
+		}
+		return strings.TrimSpace(row.Row)
+	case NodesPage:
+		return NodeIDFromKey(row.Key)
+	case ServicesPage:
+		name, _ := ServiceNameAndNamespaceFromKey(row.Key)
+		return name
+	case VolumesPage:
+		volumeID, _, _ := VolumeIDNamespaceAndPluginIDFromKey(row.Key)
+		return volumeID
+	case PluginsPage:
+		return PluginIDFromKey(row.Key)
+	case AllocFilesPage:
+		filePath, _ := FilePathAndIsDirFromKey(row.Key)
+		return filePath
+	case TaskGroupsPage:
+		return TaskGroupFromKey(row.Key)
+	case RegionsPage:
+		return RegionFromKey(row.Key)
+	}
+	return ""
+}
+
 type PageLoadedMsg struct {
 	Page        Page
 	TableHeader []string
 	AllPageData []page.Row
+	// RowStyles, if set, replaces the page's row styles
+	RowStyles map[string]lipgloss.Style
 }
 
 type ChangePageMsg struct{ NewPage Page }
 
-func getShortHelp(bindings []key.Binding) string {
-	var output string
+// getShortHelp renders bindings on a line of at most width columns. If they don't all fit, those that do are shown,
+// then the help key as "more", which opens the help overlay listing them all.
+func getShortHelp(bindings []key.Binding, width int) string {
+	var enabled []key.Binding
 	for _, km := range bindings {
-		output += style.KeyHelpKey.Render(km.Help().Key) + " " + style.KeyHelpDescription.Render(km.Help().Desc) + "    "
+		if km.Enabled() {
+			enabled = append(enabled, km)
+		}
+	}
+	var rendered []string
+	for _, km := range enabled {
+		rendered = append(rendered, renderShortHelp(km.Help().Key, km.Help().Desc))
+	}
+	if output := strings.Join(rendered, shortHelpSeparator); lipgloss.Width(output) <= width {
+		return output
+	}
+
+	more := renderShortHelp(keymap.KeyMap.Help.Help().Key, "more")
+	var output string
+	for i, km := range enabled {
+		if km.Help() == keymap.KeyMap.Help.Help() {
+			continue
+		}
+		if lipgloss.Width(output+rendered[i]+shortHelpSeparator+more) > width {
+			break
+		}
+		output += rendered[i] + shortHelpSeparator
 	}
-	output = strings.TrimSpace(output)
-	return output
+	return output + more
 }
 
-func GetPageKeyHelp(currentPage Page) string {
-	alwaysShown := []key.Binding{keymap.KeyMap.Exit}
+const shortHelpSeparator = "    "
+
+func renderShortHelp(key, desc string) string {
+	return style.KeyHelpKey.Render(key) + " " + style.KeyHelpDescription.Render(desc)
+}
+
+// GetPageKeyHelp returns the key help for currentPage, leaving out actions that permissions don't allow. Each line
+// fits in width columns.
+func GetPageKeyHelp(currentPage Page, permissions Permissions, width int) string {
+	commands, viewportKeys := pageKeys(currentPage, permissions)
+	return getShortHelp(commands, width) + "\n" + getShortHelp(viewportKeys, width)
+}
+
+// pageKeys returns the bindings shown in the header for currentPage: its commands, then keys for its viewport
+func pageKeys(currentPage Page, permissions Permissions) ([]key.Binding, []key.Binding) {
+	alwaysShown := []key.Binding{keymap.KeyMap.Exit, keymap.KeyMap.Help}
 
 	if currentPage != LoglinePage {
 		alwaysShown = append(alwaysShown, keymap.KeyMap.Reload)
@@ -138,18 +389,162 @@ func GetPageKeyHelp(currentPage Page) string {
 		alwaysShown = append(alwaysShown, keymap.KeyMap.Back)
 	}
 
+	if currentPage == JobsPage {
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Nodes)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Services)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Volumes)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Token)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Overview)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Expand)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.TaskGroups)
+		keymap.KeyMap.Scale.SetHelp(keymap.KeyMap.Scale.Help().Key, "scale")
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Scale)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Region)
+		if permissions.HasCapabilityInAnyNamespace(CapabilityDispatchJob) {
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Dispatch)
+		}
+		if permissions.HasCapabilityInAnyNamespace(CapabilitySubmitJob) {
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Stop)
+		}
+	} else if currentPage == RegionsPage {
+		keymap.KeyMap.Forward.SetHelp(keymap.KeyMap.Forward.Help().Key, "select region")
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Forward)
+	} else if currentPage == OverviewPage {
+		keymap.KeyMap.Forward.SetHelp(keymap.KeyMap.Forward.Help().Key, "view filtered page")
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Forward)
+	} else if currentPage == VolumesPage || currentPage == VolumePage {
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Plugins)
+		if currentPage == VolumePage && permissions.HasCapabilityInAnyNamespace(CapabilityCSIWriteVolume) {
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Detach)
+		}
+	} else if currentPage == ScalePage && permissions.HasCapabilityInAnyNamespace(CapabilityScaleJob) {
+		keymap.KeyMap.Scale.SetHelp(keymap.KeyMap.Scale.Help().Key, "set count")
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Scale)
+	} else if currentPage == NodePage && permissions.CanWriteNodes() {
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Drain)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Eligibility)
+	}
+
 	if currentPage == JobsPage || currentPage == AllocationsPage {
 		alwaysShown = append(alwaysShown, keymap.KeyMap.Spec)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.Datacenter)
+		if currentPage == AllocationsPage {
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Files)
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Templates)
+			alwaysShown = append(alwaysShown, keymap.KeyMap.Stats)
+			if permissions.HasCapabilityInAnyNamespace(CapabilityAllocLifecycle) {
+				alwaysShown = append(alwaysShown, keymap.KeyMap.Restart)
+			}
+		}
 	} else if currentPage == LogsPage {
 		alwaysShown = append(alwaysShown, keymap.KeyMap.StdOut)
 		alwaysShown = append(alwaysShown, keymap.KeyMap.StdErr)
+		alwaysShown = append(alwaysShown, keymap.KeyMap.StdOutAndErr)
+	}
+
+	viewportKeyMap := viewport.GetKeyMap()
+	viewportAlwaysShown := []key.Binding{viewportKeyMap.Down, viewportKeyMap.Up, viewportKeyMap.PageDown, viewportKeyMap.PageUp, viewportKeyMap.Save, keymap.KeyMap.CopyRow, keymap.KeyMap.CopyView}
+
+	// pages that list things can have rows marked to copy or act on several at once
+	if copiedName := currentPage.CopiedName(); copiedName != "" {
+		keymap.KeyMap.CopyID.SetHelp(keymap.KeyMap.CopyID.Help().Key, fmt.Sprintf("copy %s", copiedName))
+		alwaysShown = append(alwaysShown, keymap.KeyMap.CopyID)
+		viewportAlwaysShown = append(viewportAlwaysShown, viewportKeyMap.Mark, viewportKeyMap.MarkRange)
 	}
 
-	firstRow := getShortHelp(alwaysShown)
+	if currentPage.hasLongText() {
+		viewportAlwaysShown = append(viewportAlwaysShown, viewportKeyMap.Jump, keymap.KeyMap.SetBookmark, keymap.KeyMap.GoToBookmark)
+	}
 
+	return alwaysShown, viewportAlwaysShown
+}
+
+// hasLongText reports whether the page shows long text, which can be navigated by line, time and bookmarks
+func (p Page) hasLongText() bool {
+	switch p {
+	case LogsPage, JobSpecPage, AllocSpecPage, AllocFilePage, TemplatesPage:
+		return true
+	}
+	return false
+}
+
+// KeyHelpSection is a category of bindings in the help overlay
+type KeyHelpSection struct {
+	Title    string
+	Bindings []key.Binding
+}
+
+// GetPageKeyHelpSections returns every binding available on currentPage, grouped by category, leaving out actions
+// that permissions don't allow
+func GetPageKeyHelpSections(currentPage Page, permissions Permissions) []KeyHelpSection {
+	commands, _ := pageKeys(currentPage, permissions)
 	viewportKeyMap := viewport.GetKeyMap()
-	viewportAlwaysShown := []key.Binding{viewportKeyMap.Down, viewportKeyMap.Up, viewportKeyMap.PageDown, viewportKeyMap.PageUp, viewportKeyMap.Save}
-	secondRow := getShortHelp(viewportAlwaysShown)
+	withHelp := func(binding key.Binding, desc string) key.Binding {
+		binding.SetHelp(binding.Help().Key, desc)
+		return binding
+	}
 
-	return firstRow + "\n" + secondRow
+	movement := []key.Binding{
+		viewportKeyMap.Down, viewportKeyMap.Up, viewportKeyMap.Left, viewportKeyMap.Right,
+		viewportKeyMap.HalfPageDown, viewportKeyMap.HalfPageUp, viewportKeyMap.PageDown, viewportKeyMap.PageUp,
+		viewportKeyMap.Top, viewportKeyMap.Bottom,
+	}
+	if currentPage.hasLongText() {
+		movement = append(movement, viewportKeyMap.Jump, keymap.KeyMap.SetBookmark, keymap.KeyMap.GoToBookmark)
+	}
+
+	sections := []KeyHelpSection{
+		{"Commands", commands},
+		{"Moving around", movement},
+		{"Copying and saving", []key.Binding{keymap.KeyMap.CopyRow, keymap.KeyMap.CopyView, viewportKeyMap.Save}},
+	}
+	if currentPage.CopiedName() != "" {
+		sections = append(sections, KeyHelpSection{"Marking", []key.Binding{
+			viewportKeyMap.Mark, viewportKeyMap.MarkRange, viewportKeyMap.MarkDown, viewportKeyMap.MarkUp,
+			withHelp(keymap.KeyMap.Back, "clear marks"),
+		}})
+	}
+	return append(sections,
+		KeyHelpSection{"Filtering", []key.Binding{
+			keymap.KeyMap.Filter,
+			withHelp(keymap.KeyMap.Forward, "keep filter"),
+			withHelp(keymap.KeyMap.Back, "clear filter"),
+		}},
+		KeyHelpSection{"Save and jump dialogs", []key.Binding{
+			viewportKeyMap.ConfirmSave, viewportKeyMap.CancelSave, viewportKeyMap.ToggleSaveRows,
+		}},
+		KeyHelpSection{"Help", []key.Binding{
+			withHelp(keymap.KeyMap.Help, "close help"),
+			withHelp(keymap.KeyMap.Back, "close help"),
+		}},
+	)
+}
+
+// KeyHelpAsRows renders the help overlay's sections as page rows, with the keys of each binding aligned
+func KeyHelpAsRows(sections []KeyHelpSection) []page.Row {
+	keyWidth := 0
+	for _, section := range sections {
+		for _, binding := range section.Bindings {
+			if binding.Enabled() {
+				keyWidth = max(keyWidth, utf8.RuneCountInString(binding.Help().Key))
+			}
+		}
+	}
+
+	var sectionsBuilder sectionsBuilder
+	for _, section := range sections {
+		var lines []string
+		for _, binding := range section.Bindings {
+			if !binding.Enabled() {
+				continue
+			}
+			helpKey := binding.Help().Key
+			padding := strings.Repeat(" ", keyWidth-utf8.RuneCountInString(helpKey))
+			lines = append(lines, fmt.Sprintf("  %s%s    %s", helpKey, padding, binding.Help().Desc))
+		}
+		if len(lines) > 0 {
+			sectionsBuilder.addSection(section.Title, lines)
+		}
+	}
+	return sectionsBuilder.rows
 }
@@ -0,0 +1,165 @@
+package nomad
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+	"testing"
+	"wander/components/page"
+	"wander/style"
+)
+
+func TestPageForward(t *testing.T) {
+	tests := []struct {
+		page, expected Page
+	}{
+		{JobsPage, AllocationsPage},
+		{AllocationsPage, LogsPage},
+		{LogsPage, LoglinePage},
+		{AllocFilesPage, AllocFilePage},
+		{NodesPage, NodePage},
+		{ServicesPage, ServicePage},
+		{ServicePage, LogsPage},
+		{VolumesPage, VolumePage},
+		{PluginsPage, PluginPage},
+		{JobSpecPage, JobSpecPage},
+		{LoglinePage, LoglinePage},
+		{StatsPage, StatsPage},
+	}
+	for _, test := range tests {
+		if actual := test.page.Forward(); actual != test.expected {
+			t.Errorf("%s forward: got %s, expected %s", test.page, actual, test.expected)
+		}
+	}
+}
+
+// every page should lead back to the jobs page
+func TestPageBackward(t *testing.T) {
+	for page := JobsPage; page <= RegionsPage; page++ {
+		visited := map[Page]bool{page: true}
+		current := page
+		for current != JobsPage {
+			current = current.Backward()
+			if visited[current] {
+				t.Fatalf("%s backward: cycle at %s", page, current)
+			}
+			visited[current] = true
+		}
+	}
+	if JobsPage.Backward() != JobsPage {
+		t.Errorf("jobs backward: got %s", JobsPage.Backward())
+	}
+}
+
+// pages reached going forward should go back to where they came from
+func TestPageForwardThenBackward(t *testing.T) {
+	for page := JobsPage; page <= RegionsPage; page++ {
+		next := page.Forward()
+		if next == page || (page == ServicePage && next == LogsPage) {
+			// logs for a service's task go back to its allocation
+			continue
+		}
+		if back := next.Backward(); back != page {
+			t.Errorf("%s forward then backward: got %s", page, back)
+		}
+	}
+}
+
+func TestCopiedValue(t *testing.T) {
+	tests := []struct {
+		page     Page
+		row      page.Row
+		expected string
+	}{
+		{JobsPage, page.Row{Key: "web default "}, "web"},
+		{AllocationsPage, page.Row{Key: "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31 default nginx"}, "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31"},
+		{ServicePage, page.Row{Key: "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31 web"}, "0b9c6a5e-46d1-4c4a-9d2f-6a1f0e8a7c31"},
+		{LogsPage, page.Row{Key: "stdout", Row: "GET / 200   ", Data: &page.RowData{Cells: []string{"GET / 200"}}}, "GET / 200"},
+		{ServicesPage, page.Row{Key: "default web"}, "web"},
+		{VolumesPage, page.Row{Key: "default data aws-ebs"}, "data"},
+		{AllocFilesPage, page.Row{Key: "false /alloc/logs/nginx.stdout.0"}, "/alloc/logs/nginx.stdout.0"},
+		{JobSpecPage, page.Row{Key: ""}, ""},
+	}
+	for _, test := range tests {
+		if actual := test.page.CopiedValue(test.row); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.page, test.expected, actual)
+		}
+		if copies := test.page.CopiedName() != ""; copies != (test.expected != "") {
+			t.Errorf("%s: copied name doesn't match whether a value is copied", test.page)
+		}
+	}
+}
+
+func TestGetPageKeyHelpSections(t *testing.T) {
+	titles := func(sections []KeyHelpSection) map[string][]string {
+		byTitle := make(map[string][]string)
+		for _, section := range sections {
+			for _, binding := range section.Bindings {
+				byTitle[section.Title] = append(byTitle[section.Title], binding.Help().Desc)
+			}
+		}
+		return byTitle
+	}
+	contains := func(descs []string, desc string) bool {
+		for _, d := range descs {
+			if d == desc {
+				return true
+			}
+		}
+		return false
+	}
+
+	jobs := titles(GetPageKeyHelpSections(JobsPage, Permissions{}))
+	if jobs["Marking"] == nil {
+		t.Error("expected marking keys on jobs page")
+	}
+	if !contains(jobs["Commands"], "stop") {
+		t.Error("expected stop on jobs page without restrictions")
+	}
+	if contains(jobs["Moving around"], "go to line/time") {
+		t.Error("expected no jump on jobs page")
+	}
+
+	restricted := titles(GetPageKeyHelpSections(JobsPage, Permissions{restricted: true}))
+	if contains(restricted["Commands"], "stop") {
+		t.Error("expected no stop without permission")
+	}
+
+	spec := titles(GetPageKeyHelpSections(JobSpecPage, Permissions{}))
+	if spec["Marking"] != nil {
+		t.Error("expected no marking keys on job spec page")
+	}
+	if !contains(spec["Moving around"], "go to line/time") {
+		t.Error("expected jump on job spec page")
+	}
+	if !contains(spec["Help"], "close help") {
+		t.Error("expected help section")
+	}
+}
+
+func TestKeyHelpAsRowsSkipsDisabled(t *testing.T) {
+	sections := GetPageKeyHelpSections(LogsPage, Permissions{})
+	sections[0].Bindings[0].SetEnabled(false)
+	disabled := sections[0].Bindings[0].Help()
+	for _, row := range KeyHelpAsRows(sections) {
+		if strings.HasPrefix(strings.TrimSpace(row.Row), disabled.Key+" ") && strings.HasSuffix(row.Row, disabled.Desc) {
+			t.Errorf("expected disabled binding to be skipped, got %q", row.Row)
+		}
+	}
+}
+
+func TestGetPageKeyHelpFitsWidth(t *testing.T) {
+	for _, width := range []int{40, 80, 120} {
+		for i, line := range strings.Split(GetPageKeyHelp(JobsPage, Permissions{}, width), "\n") {
+			if lipgloss.Width(line) > width {
+				t.Errorf("line %d is %d columns, expected at most %d", i, lipgloss.Width(line), width)
+			}
+			if width == 40 && !strings.HasSuffix(line, style.KeyHelpDescription.Render("more")) {
+				t.Errorf("expected line %d to end with more, got %q", i, line)
+			}
+		}
+	}
+
+	if help := GetPageKeyHelp(JobsPage, Permissions{}, 1000); strings.Contains(help, "more") {
+		t.Errorf("expected all keys to fit, got %q", help)
+	}
+}
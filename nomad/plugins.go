@@ -0,0 +1,159 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"time"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// pluginResponseEntry is returned from GET /v1/plugins?type=csi
+// https://www.nomadproject.io/api-docs/plugins#list-plugins
+type pluginResponseEntry struct {
+	ID                  string `json:"ID"`
+	Provider            string `json:"Provider"`
+	ControllerRequired  bool   `json:"ControllerRequired"`
+	ControllersHealthy  int    `json:"ControllersHealthy"`
+	ControllersExpected int    `json:"ControllersExpected"`
+	NodesHealthy        int    `json:"NodesHealthy"`
+	NodesExpected       int    `json:"NodesExpected"`
+}
+
+type pluginInstanceInfo struct {
+	PluginID                 string    `json:"PluginID"`
+	AllocID                  string    `json:"AllocID"`
+	Healthy                  bool      `json:"Healthy"`
+	HealthDescription        string    `json:"HealthDescription"`
+	UpdateTime               time.Time `json:"UpdateTime"`
+	RequiresControllerPlugin bool      `json:"RequiresControllerPlugin"`
+}
+
+// pluginResponse is returned from GET /v1/plugin/csi/:plugin_id
+// https://www.nomadproject.io/api-docs/plugins#read-plugin
+type pluginResponse struct {
+	pluginResponseEntry
+	Version     string                        `json:"Version"`
+	Controllers map[string]pluginInstanceInfo `json:"Controllers"`
+	Nodes       map[string]pluginInstanceInfo `json:"Nodes"`
+}
+
+// CSIPlugins lists the CSI plugins
+// https://www.nomadproject.io/api-docs/plugins#list-plugins
+func (c *Client) CSIPlugins(ctx context.Context) ([]pluginResponseEntry, error) {
+	var plugins []pluginResponseEntry
+	err := c.getJSON(ctx, "/v1/plugins", map[string]string{"type": "csi"}, &plugins)
+	return plugins, err
+}
+
+// CSIPlugin reads a CSI plugin
+// https://www.nomadproject.io/api-docs/plugins#read-plugin
+func (c *Client) CSIPlugin(ctx context.Context, pluginID string) (pluginResponse, error) {
+	var plugin pluginResponse
+	err := c.getJSON(ctx, "/v1/plugin/csi/"+pluginID, nil, &plugin)
+	return plugin, err
+}
+
+func FetchPlugins(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		pluginsResponse, err := client.CSIPlugins(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(pluginsResponse, func(x, y int) bool {
+			return pluginsResponse[x].ID < pluginsResponse[y].ID
+		})
+
+		tableHeader, allPageData := pluginsAsTable(pluginsResponse)
+		return PageLoadedMsg{Page: PluginsPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+func FetchPlugin(ctx context.Context, client *Client, pluginID string) tea.Cmd {
+	return func() tea.Msg {
+		plugin, err := client.CSIPlugin(ctx, pluginID)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		return PageLoadedMsg{
+			Page:        PluginPage,
+			TableHeader: []string{},
+			AllPageData: pluginAsSections(plugin),
+		}
+	}
+}
+
+func pluginsAsTable(plugins []pluginResponseEntry) ([]string, []page.Row) {
+	var pluginRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range plugins {
+		pluginRows = append(pluginRows, []string{
+			row.ID,
+			row.Provider,
+			strconv.FormatBool(row.ControllerRequired),
+			fmt.Sprintf("%d/%d", row.ControllersHealthy, row.ControllersExpected),
+			fmt.Sprintf("%d/%d", row.NodesHealthy, row.NodesExpected),
+		})
+		keys = append(keys, toPluginsKey(row))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"ID", "Provider", "Controller Required", "Controllers Healthy", "Nodes Healthy"}
+	tableHeader, rows := tableAsRows(columns, pluginRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func pluginAsSections(plugin pluginResponse) []page.Row {
+	var sections sectionsBuilder
+
+	sections.addTable("Plugin", []string{"Field", "Value"}, [][]string{
+		{"ID", plugin.ID},
+		{"Provider", fmt.Sprintf("%s %s", plugin.Provider, plugin.Version)},
+		{"Controller Required", strconv.FormatBool(plugin.ControllerRequired)},
+		{"Controllers Healthy", fmt.Sprintf("%d/%d", plugin.ControllersHealthy, plugin.ControllersExpected)},
+		{"Nodes Healthy", fmt.Sprintf("%d/%d", plugin.NodesHealthy, plugin.NodesExpected)},
+	})
+
+	pluginInstancesColumns := []string{"Node", "Alloc ID", "Healthy", "Description", "Updated"}
+	sections.addTable("Controllers", pluginInstancesColumns, pluginInstancesAsRows(plugin.Controllers))
+	sections.addTable("Nodes", pluginInstancesColumns, pluginInstancesAsRows(plugin.Nodes))
+
+	return sections.rows
+}
+
+func pluginInstancesAsRows(instances map[string]pluginInstanceInfo) [][]string {
+	var nodeIDs []string
+	for nodeID := range instances {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	var rows [][]string
+	for _, nodeID := range nodeIDs {
+		instance := instances[nodeID]
+		rows = append(rows, []string{
+			formatter.ShortAllocID(nodeID),
+			formatter.ShortAllocID(instance.AllocID),
+			strconv.FormatBool(instance.Healthy),
+			instance.HealthDescription,
+			formatter.FormatTime(instance.UpdateTime),
+		})
+	}
+	return rows
+}
+
+func toPluginsKey(pluginResponseEntry pluginResponseEntry) string {
+	return pluginResponseEntry.ID
+}
+
+func PluginIDFromKey(key string) string {
+	return key
+}
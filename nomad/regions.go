@@ -0,0 +1,72 @@
+package nomad
+
+import (
+	"context"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"sort"
+	"wander/message"
+	"wander/style"
+)
+
+// Regions lists the regions of a federated cluster
+// https://www.nomadproject.io/api-docs/regions
+func (c *Client) Regions(ctx context.Context) ([]string, error) {
+	var regions []string
+	err := c.getJSON(ctx, "/v1/regions", nil, &regions)
+	return regions, err
+}
+
+// agentSelfResponse is the part of GET /v1/agent/self that describes the agent's configuration
+// https://www.nomadproject.io/api-docs/agent#query-self
+type agentSelfResponse struct {
+	Config struct {
+		Region string `json:"Region"`
+	} `json:"config"`
+}
+
+// AgentRegion returns the region of the agent requests are made to, which they go to unless a region is set
+// https://www.nomadproject.io/api-docs/agent#query-self
+func (c *Client) AgentRegion(ctx context.Context) (string, error) {
+	var agentSelf agentSelfResponse
+	err := c.getJSON(ctx, "/v1/agent/self", nil, &agentSelf)
+	return agentSelf.Config.Region, err
+}
+
+// FetchRegions lists the regions of a federated cluster, highlighting the current region
+func FetchRegions(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		regions, err := client.Regions(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+		sort.Strings(regions)
+
+		currentRegion := client.Region()
+		if currentRegion == "" {
+			// reading the agent needs agent:read, so without it the regions are still listed, just with none current
+			currentRegion, _ = client.AgentRegion(ctx)
+		}
+
+		var regionRows [][]string
+		for _, name := range regions {
+			current := ""
+			if name == currentRegion {
+				current = "current"
+			}
+			regionRows = append(regionRows, []string{name, current})
+		}
+		tableHeader, rows := tableAsRows([]string{"Region", ""}, regionRows, regions, nil)
+
+		return PageLoadedMsg{
+			Page:        RegionsPage,
+			TableHeader: tableHeader,
+			AllPageData: rows,
+			RowStyles:   map[string]lipgloss.Style{currentRegion: style.Bold},
+		}
+	}
+}
+
+func RegionFromKey(key string) string {
+	return key
+}
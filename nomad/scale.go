@@ -0,0 +1,247 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"strings"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// scaleStatusResponse is returned from GET /v1/job/:job_id/scale
+// https://www.nomadproject.io/api-docs/jobs#read-job-scale-status
+type scaleStatusResponse struct {
+	JobID      string `json:"JobID"`
+	JobStopped bool   `json:"JobStopped"`
+	TaskGroups map[string]struct {
+		Desired   int `json:"Desired"`
+		Placed    int `json:"Placed"`
+		Running   int `json:"Running"`
+		Healthy   int `json:"Healthy"`
+		Unhealthy int `json:"Unhealthy"`
+		Events    []struct {
+			Time          int64  `json:"Time"`
+			Count         *int   `json:"Count"`
+			PreviousCount int    `json:"PreviousCount"`
+			Message       string `json:"Message"`
+			Error         bool   `json:"Error"`
+		} `json:"Events"`
+	} `json:"TaskGroups"`
+}
+
+// jobScalingResponse is the part of GET /v1/job/:job_id with each task group's scaling policy
+type jobScalingResponse struct {
+	TaskGroups []struct {
+		Name    string `json:"Name"`
+		Scaling *struct {
+			Min     *int `json:"Min"`
+			Max     *int `json:"Max"`
+			Enabled bool `json:"Enabled"`
+		} `json:"Scaling"`
+	} `json:"TaskGroups"`
+}
+
+// ScalableTaskGroup is a task group's current count and the bounds of its scaling policy, if any
+type ScalableTaskGroup struct {
+	Name     string
+	Desired  int
+	Min, Max *int
+}
+
+// Bounds formats the scaling policy bounds, e.g. 1-10
+func (g ScalableTaskGroup) Bounds() string {
+	if g.Min == nil && g.Max == nil {
+		return ""
+	}
+	min, max := "0", "∞"
+	if g.Min != nil {
+		min = strconv.Itoa(*g.Min)
+	}
+	if g.Max != nil {
+		max = strconv.Itoa(*g.Max)
+	}
+	return fmt.Sprintf("%s-%s", min, max)
+}
+
+// ParseCount parses a count as entered by a user, checking it's within the scaling policy bounds
+func (g ScalableTaskGroup) ParseCount(s string) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid count %q", s)
+	}
+	if (g.Min != nil && count < *g.Min) || (g.Max != nil && count > *g.Max) {
+		return 0, fmt.Errorf("count %d outside scaling policy bounds %s", count, g.Bounds())
+	}
+	return count, nil
+}
+
+type ScaleLoadedMsg struct {
+	JobID       string
+	TaskGroups  map[string]ScalableTaskGroup
+	AllPageData []page.Row
+}
+
+// ScaleStatus reads the counts and scaling events of a job's task groups
+// https://www.nomadproject.io/api-docs/jobs#read-job-scale-status
+func (c *Client) ScaleStatus(ctx context.Context, jobID, namespace string) (scaleStatusResponse, error) {
+	var scaleStatus scaleStatusResponse
+	err := c.getJSON(ctx, "/v1/job/"+jobID+"/scale", namespaceParams(namespace), &scaleStatus)
+	return scaleStatus, err
+}
+
+// Scale sets the count of a task group, recording scaleMessage in the job's scaling events
+// https://www.nomadproject.io/api-docs/jobs#scale-task-group
+func (c *Client) Scale(ctx context.Context, jobID, namespace, taskGroup string, count int, scaleMessage string) error {
+	request := struct {
+		Count   int               `json:"Count"`
+		Target  map[string]string `json:"Target"`
+		Message string            `json:"Message,omitempty"`
+	}{
+		Count:   count,
+		Target:  map[string]string{"Group": taskGroup},
+		Message: scaleMessage,
+	}
+	_, err := c.post(ctx, "/v1/job/"+jobID+"/scale", namespaceParams(namespace), request)
+	return err
+}
+
+func FetchScale(ctx context.Context, client *Client, jobID, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		scaleStatus, err := client.ScaleStatus(ctx, jobID, namespace)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		body, err := client.Job(ctx, jobID, namespace)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var jobScaling jobScalingResponse
+		if err := json.Unmarshal(body, &jobScaling); err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		taskGroups := make(map[string]ScalableTaskGroup)
+		for name, status := range scaleStatus.TaskGroups {
+			taskGroups[name] = ScalableTaskGroup{Name: name, Desired: status.Desired}
+		}
+		for _, taskGroup := range jobScaling.TaskGroups {
+			if scalable, exists := taskGroups[taskGroup.Name]; exists && taskGroup.Scaling != nil {
+				scalable.Min, scalable.Max = taskGroup.Scaling.Min, taskGroup.Scaling.Max
+				taskGroups[taskGroup.Name] = scalable
+			}
+		}
+
+		return ScaleLoadedMsg{
+			JobID:       jobID,
+			TaskGroups:  taskGroups,
+			AllPageData: scaleAsSections(scaleStatus, jobScaling),
+		}
+	}
+}
+
+func scaleAsSections(scaleStatus scaleStatusResponse, jobScaling jobScalingResponse) []page.Row {
+	var sections sectionsBuilder
+
+	var taskGroupNames []string
+	for name := range scaleStatus.TaskGroups {
+		taskGroupNames = append(taskGroupNames, name)
+	}
+	sort.Strings(taskGroupNames)
+
+	policies := make(map[string]string)
+	for _, taskGroup := range jobScaling.TaskGroups {
+		if taskGroup.Scaling != nil {
+			scalable := ScalableTaskGroup{Min: taskGroup.Scaling.Min, Max: taskGroup.Scaling.Max}
+			policies[taskGroup.Name] = scalable.Bounds()
+			if !taskGroup.Scaling.Enabled {
+				policies[taskGroup.Name] += " (disabled)"
+			}
+		}
+	}
+
+	var taskGroupRows [][]string
+	var taskGroupKeys []string
+	type scaleEvent struct {
+		time                          int64
+		taskGroup, count, message, ok string
+	}
+	var events []scaleEvent
+	for _, name := range taskGroupNames {
+		status := scaleStatus.TaskGroups[name]
+		taskGroupRows = append(taskGroupRows, []string{
+			name,
+			strconv.Itoa(status.Desired),
+			strconv.Itoa(status.Placed),
+			strconv.Itoa(status.Running),
+			strconv.Itoa(status.Healthy),
+			strconv.Itoa(status.Unhealthy),
+			policies[name],
+		})
+		taskGroupKeys = append(taskGroupKeys, toScaleTaskGroupKey(name))
+
+		for _, event := range status.Events {
+			count := ""
+			if event.Count != nil {
+				count = fmt.Sprintf("%d → %d", event.PreviousCount, *event.Count)
+			}
+			ok := "ok"
+			if event.Error {
+				ok = "error"
+			}
+			events = append(events, scaleEvent{event.Time, name, count, event.Message, ok})
+		}
+	}
+	sections.addTableWithKeys(
+		"Task Groups",
+		[]string{"Task Group", "Desired", "Placed", "Running", "Healthy", "Unhealthy", "Scaling Policy"},
+		taskGroupRows,
+		taskGroupKeys,
+	)
+
+	sort.Slice(events, func(x, y int) bool {
+		return events[x].time > events[y].time
+	})
+	var eventRows [][]string
+	for _, event := range events {
+		eventRows = append(eventRows, []string{
+			formatter.FormatTimeNs(event.time),
+			event.taskGroup,
+			event.count,
+			event.ok,
+			event.message,
+		})
+	}
+	sections.addTable("Scaling Events", []string{"Time", "Task Group", "Count", "Result", "Message"}, eventRows)
+
+	return sections.rows
+}
+
+func toScaleTaskGroupKey(taskGroup string) string {
+	return "taskgroup " + taskGroup
+}
+
+// TaskGroupFromScaleKey returns the task group of a row in the scale page's task group table.
+// Returns false for other rows.
+func TaskGroupFromScaleKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, "taskgroup ") {
+		return "", false
+	}
+	return strings.TrimPrefix(key, "taskgroup "), true
+}
+
+// ScaleTaskGroup sets the count of a task group, recording scaleMessage in the job's scaling events
+func ScaleTaskGroup(ctx context.Context, client *Client, jobID, namespace, taskGroup string, count int, scaleMessage string) tea.Cmd {
+	return func() tea.Msg {
+		if err := client.Scale(ctx, jobID, namespace, taskGroup, count, scaleMessage); err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+		return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("Scaled %s group %s to %d", jobID, taskGroup, count)}
+	}
+}
@@ -0,0 +1,271 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"wander/components/page"
+	"wander/constants"
+	"wander/formatter"
+	"wander/message"
+)
+
+// servicesResponseEntry is returned from GET /v1/services
+// https://www.nomadproject.io/api-docs/services#list-services
+type servicesResponseEntry struct {
+	Namespace string `json:"Namespace"`
+	Services  []struct {
+		ServiceName string   `json:"ServiceName"`
+		Tags        []string `json:"Tags"`
+	} `json:"Services"`
+}
+
+// serviceInstanceResponseEntry is returned from GET /v1/service/:service_name
+// https://www.nomadproject.io/api-docs/services#read-service
+type serviceInstanceResponseEntry struct {
+	ID          string   `json:"ID"`
+	ServiceName string   `json:"ServiceName"`
+	Namespace   string   `json:"Namespace"`
+	NodeID      string   `json:"NodeID"`
+	Datacenter  string   `json:"Datacenter"`
+	JobID       string   `json:"JobID"`
+	AllocID     string   `json:"AllocID"`
+	Tags        []string `json:"Tags"`
+	Address     string   `json:"Address"`
+	Port        int      `json:"Port"`
+}
+
+// serviceRowEntry is a service with the number of its registered instances
+type serviceRowEntry struct {
+	Name, Namespace string
+	Tags            []string
+	Instances       int
+}
+
+// Services lists the services registered with Nomad's service discovery in every namespace
+// https://www.nomadproject.io/api-docs/services#list-services
+func (c *Client) Services(ctx context.Context) ([]servicesResponseEntry, error) {
+	var services []servicesResponseEntry
+	err := c.getJSON(ctx, "/v1/services", map[string]string{"namespace": "*"}, &services)
+	return services, err
+}
+
+// ServiceInstances lists the registered instances of a service
+// https://www.nomadproject.io/api-docs/services#read-service
+func (c *Client) ServiceInstances(ctx context.Context, serviceName, namespace string) ([]serviceInstanceResponseEntry, error) {
+	var instances []serviceInstanceResponseEntry
+	err := c.getJSON(ctx, "/v1/service/"+serviceName, namespaceParams(namespace), &instances)
+	return instances, err
+}
+
+func FetchServices(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		servicesResponse, err := client.Services(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var serviceRowEntries []serviceRowEntry
+		for _, namespace := range servicesResponse {
+			for _, service := range namespace.Services {
+				serviceRowEntries = append(serviceRowEntries, serviceRowEntry{
+					Name:      service.ServiceName,
+					Namespace: namespace.Namespace,
+					Tags:      service.Tags,
+				})
+			}
+		}
+		if err := countServiceInstances(ctx, client, serviceRowEntries); err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(serviceRowEntries, func(x, y int) bool {
+			firstService := serviceRowEntries[x]
+			secondService := serviceRowEntries[y]
+			if firstService.Name == secondService.Name {
+				return firstService.Namespace < secondService.Namespace
+			}
+			return firstService.Name < secondService.Name
+		})
+
+		tableHeader, allPageData := servicesAsTable(serviceRowEntries)
+		return PageLoadedMsg{Page: ServicesPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+// countServiceInstances sets the number of instances of each service. Counting takes a request per service, so a few
+// are made at once.
+func countServiceInstances(ctx context.Context, client *Client, services []serviceRowEntry) error {
+	errs := make([]error, len(services))
+	requests := make(chan struct{}, constants.ConcurrentServiceRequests)
+	var wg sync.WaitGroup
+	for idx := range services {
+		wg.Add(1)
+		requests <- struct{}{}
+		go func(service *serviceRowEntry, err *error) {
+			defer func() {
+				<-requests
+				wg.Done()
+			}()
+			instances, instancesErr := client.ServiceInstances(ctx, service.Name, service.Namespace)
+			service.Instances, *err = len(instances), instancesErr
+		}(&services[idx], &errs[idx])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func FetchService(ctx context.Context, client *Client, serviceName, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		instances, err := client.ServiceInstances(ctx, serviceName, namespace)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(instances, func(x, y int) bool {
+			firstInstance := instances[x]
+			secondInstance := instances[y]
+			if firstInstance.Address == secondInstance.Address {
+				return firstInstance.Port < secondInstance.Port
+			}
+			return firstInstance.Address < secondInstance.Address
+		})
+
+		tableHeader, allPageData := serviceInstancesAsTable(instances)
+		return PageLoadedMsg{Page: ServicePage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+func servicesAsTable(services []serviceRowEntry) ([]string, []page.Row) {
+	var serviceRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range services {
+		serviceRows = append(serviceRows, []string{
+			row.Name,
+			row.Namespace,
+			strings.Join(row.Tags, ","),
+			strconv.Itoa(row.Instances),
+		})
+		keys = append(keys, toServicesKey(row))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"Service", "Namespace", "Tags", "Instances"}
+	tableHeader, rows := tableAsRows(columns, serviceRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func serviceInstancesAsTable(instances []serviceInstanceResponseEntry) ([]string, []page.Row) {
+	var instanceRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range instances {
+		instanceRows = append(instanceRows, []string{
+			row.Address,
+			strconv.Itoa(row.Port),
+			formatter.ShortAllocID(row.AllocID),
+			row.JobID,
+			formatter.ShortAllocID(row.NodeID),
+			row.Datacenter,
+			strings.Join(row.Tags, ","),
+		})
+		keys = append(keys, toServiceInstancesKey(row))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"Address", "Port", "Alloc ID", "Job", "Node", "Datacenter", "Tags"}
+	tableHeader, rows := tableAsRows(columns, instanceRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func toServicesKey(serviceRowEntry serviceRowEntry) string {
+	return serviceRowEntry.Namespace + " " + serviceRowEntry.Name
+}
+
+func ServiceNameAndNamespaceFromKey(key string) (string, string) {
+	split := strings.SplitN(key, " ", 2)
+	return split[1], split[0]
+}
+
+func toServiceInstancesKey(instance serviceInstanceResponseEntry) string {
+	return instance.AllocID + " " + instance.JobID
+}
+
+func AllocIDAndJobIDFromKey(key string) (string, string) {
+	split := strings.SplitN(key, " ", 2)
+	return split[0], split[1]
+}
+
+// allocServicesResponse is the part of GET /v1/allocation/:alloc_id needed to find which task registers a service
+type allocServicesResponse struct {
+	TaskGroup  string                     `json:"TaskGroup"`
+	TaskStates map[string]json.RawMessage `json:"TaskStates"`
+	Job        struct {
+		TaskGroups []struct {
+			Name  string `json:"Name"`
+			Tasks []struct {
+				Name     string `json:"Name"`
+				Services []struct {
+					Name string `json:"Name"`
+				} `json:"Services"`
+			} `json:"Tasks"`
+		} `json:"TaskGroups"`
+	} `json:"Job"`
+}
+
+type ServiceTaskLoadedMsg struct {
+	AllocID, TaskName string
+}
+
+// FetchServiceTask finds the task of an allocation that registers serviceName. Services defined at the group level
+// belong to no task, in which case the first task of the allocation is used.
+func FetchServiceTask(ctx context.Context, client *Client, allocID, serviceName string) tea.Cmd {
+	return func() tea.Msg {
+		body, err := client.Allocation(ctx, allocID)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var allocResponse allocServicesResponse
+		if err := json.Unmarshal(body, &allocResponse); err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		for _, taskGroup := range allocResponse.Job.TaskGroups {
+			if taskGroup.Name != allocResponse.TaskGroup {
+				continue
+			}
+			for _, task := range taskGroup.Tasks {
+				for _, service := range task.Services {
+					if service.Name == serviceName {
+						return ServiceTaskLoadedMsg{AllocID: allocID, TaskName: task.Name}
+					}
+				}
+			}
+		}
+
+		var taskNames []string
+		for taskName := range allocResponse.TaskStates {
+			taskNames = append(taskNames, taskName)
+		}
+		if len(taskNames) == 0 {
+			return message.ActionStatusMsg{Err: fmt.Sprintf("no tasks found for allocation %s", formatter.ShortAllocID(allocID))}
+		}
+		sort.Strings(taskNames)
+		return ServiceTaskLoadedMsg{AllocID: allocID, TaskName: taskNames[0]}
+	}
+}
@@ -0,0 +1,214 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"time"
+	"wander/components/page"
+	"wander/constants"
+	"wander/formatter"
+)
+
+// StatsHighMemoryKey is the row key of tasks using a large share of their allocated memory
+const StatsHighMemoryKey = "high memory"
+
+// allocStatsResponse is returned from GET /v1/client/allocation/:alloc_id/stats
+// https://www.nomadproject.io/api-docs/client#read-allocation-statistics
+type allocStatsResponse struct {
+	Tasks map[string]struct {
+		ResourceUsage struct {
+			CpuStats struct {
+				TotalTicks float64 `json:"TotalTicks"`
+			} `json:"CpuStats"`
+			MemoryStats struct {
+				RSS   uint64 `json:"RSS"`
+				Cache uint64 `json:"Cache"`
+				Usage uint64 `json:"Usage"`
+			} `json:"MemoryStats"`
+		} `json:"ResourceUsage"`
+	} `json:"Tasks"`
+}
+
+// allocResourcesResponse is the part of GET /v1/allocation/:alloc_id that describes the resources of its tasks
+type allocResourcesResponse struct {
+	TaskGroup string `json:"TaskGroup"`
+	Job       struct {
+		TaskGroups []struct {
+			Name  string `json:"Name"`
+			Tasks []struct {
+				Name      string `json:"Name"`
+				Resources struct {
+					CPU      int `json:"CPU"`
+					MemoryMB int `json:"MemoryMB"`
+				} `json:"Resources"`
+			} `json:"Tasks"`
+		} `json:"TaskGroups"`
+	} `json:"Job"`
+}
+
+// TaskStats is a single sample of a task's resource usage, along with the resources allocated to it in the job spec
+type TaskStats struct {
+	TaskName             string
+	CpuMHz               float64
+	RSSBytes, CacheBytes uint64
+	AllocatedCpuMHz      int
+	AllocatedMemoryMB    int
+}
+
+func (s TaskStats) cpuPercentOfAllocated() float64 {
+	return percentOf(s.CpuMHz, float64(s.AllocatedCpuMHz))
+}
+
+func (s TaskStats) memoryPercentOfAllocated() float64 {
+	return percentOf(float64(s.RSSBytes), float64(s.AllocatedMemoryMB)*1024*1024)
+}
+
+// StatsHistory holds the samples of each task's resource usage, oldest first
+type StatsHistory map[string][]TaskStats
+
+// Add appends samples to the history, keeping at most constants.StatsHistoryLength samples per task
+func (h StatsHistory) Add(samples []TaskStats) {
+	for _, sample := range samples {
+		taskHistory := append(h[sample.TaskName], sample)
+		if len(taskHistory) > constants.StatsHistoryLength {
+			taskHistory = taskHistory[len(taskHistory)-constants.StatsHistoryLength:]
+		}
+		h[sample.TaskName] = taskHistory
+	}
+}
+
+type StatsLoadedMsg struct {
+	AllocID string
+	Stats   []TaskStats
+}
+
+// StatsErrorMsg reports a failed poll of allocation stats, which leaves the samples so far shown and polling going
+type StatsErrorMsg struct {
+	AllocID string
+	Err     error
+}
+
+// StatsTickMsg triggers the next poll of allocation stats. PollID identifies the polling loop that sent it.
+type StatsTickMsg struct{ PollID int }
+
+func GetStatsTickCmd(pollID int) tea.Cmd {
+	return tea.Tick(constants.StatsPollInterval, func(t time.Time) tea.Msg { return StatsTickMsg{PollID: pollID} })
+}
+
+// AllocStats reads the current resource usage of an allocation's tasks
+// https://www.nomadproject.io/api-docs/client#read-allocation-statistics
+func (c *Client) AllocStats(ctx context.Context, allocID string) (allocStatsResponse, error) {
+	var stats allocStatsResponse
+	err := c.getJSON(ctx, "/v1/client/allocation/"+allocID+"/stats", nil, &stats)
+	return stats, err
+}
+
+func FetchStats(ctx context.Context, client *Client, allocID string) tea.Cmd {
+	return func() tea.Msg {
+		statsResponse, err := client.AllocStats(ctx, allocID)
+		if err != nil {
+			return StatsErrorMsg{AllocID: allocID, Err: err}
+		}
+
+		body, err := client.Allocation(ctx, allocID)
+		if err != nil {
+			return StatsErrorMsg{AllocID: allocID, Err: err}
+		}
+
+		var resourcesResponse allocResourcesResponse
+		if err := json.Unmarshal(body, &resourcesResponse); err != nil {
+			return StatsErrorMsg{AllocID: allocID, Err: err}
+		}
+
+		var stats []TaskStats
+		for taskName, task := range statsResponse.Tasks {
+			memoryStats := task.ResourceUsage.MemoryStats
+			rss := memoryStats.RSS
+			if rss == 0 {
+				// cgroups v2 only reports total usage
+				rss = memoryStats.Usage
+			}
+			taskStats := TaskStats{
+				TaskName:   taskName,
+				CpuMHz:     task.ResourceUsage.CpuStats.TotalTicks,
+				RSSBytes:   rss,
+				CacheBytes: memoryStats.Cache,
+			}
+			for _, taskGroup := range resourcesResponse.Job.TaskGroups {
+				if taskGroup.Name != resourcesResponse.TaskGroup {
+					continue
+				}
+				for _, specTask := range taskGroup.Tasks {
+					if specTask.Name == taskName {
+						taskStats.AllocatedCpuMHz = specTask.Resources.CPU
+						taskStats.AllocatedMemoryMB = specTask.Resources.MemoryMB
+					}
+				}
+			}
+			stats = append(stats, taskStats)
+		}
+
+		sort.Slice(stats, func(x, y int) bool {
+			return stats[x].TaskName < stats[y].TaskName
+		})
+
+		return StatsLoadedMsg{AllocID: allocID, Stats: stats}
+	}
+}
+
+func StatsAsTable(history StatsHistory) ([]string, []page.Row) {
+	var taskNames []string
+	for taskName := range history {
+		taskNames = append(taskNames, taskName)
+	}
+	sort.Strings(taskNames)
+
+	var statsRows [][]string
+	var keys []string
+	for _, taskName := range taskNames {
+		taskHistory := history[taskName]
+		if len(taskHistory) == 0 {
+			continue
+		}
+		current := taskHistory[len(taskHistory)-1]
+
+		var cpuHistory, memoryHistory []float64
+		for _, sample := range taskHistory {
+			cpuHistory = append(cpuHistory, sample.CpuMHz)
+			memoryHistory = append(memoryHistory, float64(sample.RSSBytes))
+		}
+		allocatedMemoryBytes := float64(current.AllocatedMemoryMB) * 1024 * 1024
+
+		statsRows = append(statsRows, []string{
+			taskName,
+			fmt.Sprintf("%.0f / %d MHz", current.CpuMHz, current.AllocatedCpuMHz),
+			fmt.Sprintf("%.1f%%", current.cpuPercentOfAllocated()),
+			formatter.Sparkline(cpuHistory, float64(current.AllocatedCpuMHz)),
+			fmt.Sprintf("%s / %s", formatter.FormatBytes(int64(current.RSSBytes)), formatter.FormatBytes(int64(allocatedMemoryBytes))),
+			formatter.FormatBytes(int64(current.CacheBytes)),
+			fmt.Sprintf("%.1f%%", current.memoryPercentOfAllocated()),
+			formatter.Sparkline(memoryHistory, allocatedMemoryBytes),
+		})
+
+		key := ""
+		if current.memoryPercentOfAllocated() >= constants.StatsHighMemoryPercent {
+			key = StatsHighMemoryKey
+		}
+		keys = append(keys, key)
+	}
+
+	columns := []string{"Task", "CPU", "CPU %", "CPU History", "Memory (RSS)", "Cache", "Memory %", "Memory History"}
+	tableHeader, rows := tableAsRows(columns, statsRows, keys, nil)
+
+	return tableHeader, rows
+}
+
+func percentOf(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return value / total * 100
+}
@@ -0,0 +1,134 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"sort"
+	"strconv"
+	"wander/components/page"
+	"wander/message"
+	"wander/style"
+)
+
+// taskGroupSummary counts the allocations of a task group by status
+// https://www.nomadproject.io/api-docs/jobs#read-job-summary
+type taskGroupSummary struct {
+	Queued   int `json:"Queued"`
+	Complete int `json:"Complete"`
+	Failed   int `json:"Failed"`
+	Running  int `json:"Running"`
+	Starting int `json:"Starting"`
+	Lost     int `json:"Lost"`
+}
+
+func (s taskGroupSummary) add(other taskGroupSummary) taskGroupSummary {
+	return taskGroupSummary{
+		Queued:   s.Queued + other.Queued,
+		Complete: s.Complete + other.Complete,
+		Failed:   s.Failed + other.Failed,
+		Running:  s.Running + other.Running,
+		Starting: s.Starting + other.Starting,
+		Lost:     s.Lost + other.Lost,
+	}
+}
+
+// expected is the number of allocations that should be running once those queued and starting are placed
+func (s taskGroupSummary) expected() int {
+	return s.Queued + s.Starting + s.Running
+}
+
+// running formats the running count against those expected, e.g. 3/5
+func (s taskGroupSummary) running() string {
+	if s.expected() == 0 {
+		return strconv.Itoa(s.Running)
+	}
+	return fmt.Sprintf("%d/%d", s.Running, s.expected())
+}
+
+// countColumns are the summary counts as shown in a table, in the order of summaryColumns
+func (s taskGroupSummary) countColumns() []string {
+	return []string{
+		strconv.Itoa(s.Queued),
+		strconv.Itoa(s.Starting),
+		s.running(),
+		strconv.Itoa(s.Failed),
+		strconv.Itoa(s.Complete),
+		strconv.Itoa(s.Lost),
+	}
+}
+
+var summaryColumns = []string{"Queued", "Starting", "Running", "Failed", "Complete", "Lost"}
+
+// healthStyle colors a summary by how much of what's expected is running. Returns false if nothing is expected and
+// nothing failed, e.g. for completed batch jobs.
+func (s taskGroupSummary) healthStyle() (lipgloss.Style, bool) {
+	expected := s.expected()
+	switch {
+	case expected > 0 && s.Running == expected:
+		return style.Healthy, true
+	case s.Running == 0 && (expected > 0 || (s.Failed+s.Lost > 0 && s.Complete == 0)):
+		return style.Unhealthy, true
+	case s.Running < expected:
+		return style.Degraded, true
+	}
+	return lipgloss.Style{}, false
+}
+
+func totalSummary(summary map[string]taskGroupSummary) taskGroupSummary {
+	var total taskGroupSummary
+	for _, groupSummary := range summary {
+		total = total.add(groupSummary)
+	}
+	return total
+}
+
+// JobSummary reads the allocation counts of each of a job's task groups
+// https://www.nomadproject.io/api-docs/jobs#read-job-summary
+func (c *Client) JobSummary(ctx context.Context, jobID, namespace string) (map[string]taskGroupSummary, error) {
+	var summaryResponse struct {
+		Summary map[string]taskGroupSummary `json:"Summary"`
+	}
+	err := c.getJSON(ctx, "/v1/job/"+jobID+"/summary", namespaceParams(namespace), &summaryResponse)
+	return summaryResponse.Summary, err
+}
+
+func FetchTaskGroups(ctx context.Context, client *Client, jobID, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		summary, err := client.JobSummary(ctx, jobID, namespace)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		tableHeader, allPageData, rowStyles := taskGroupsAsTable(summary)
+		return PageLoadedMsg{Page: TaskGroupsPage, TableHeader: tableHeader, AllPageData: allPageData, RowStyles: rowStyles}
+	}
+}
+
+func taskGroupsAsTable(summary map[string]taskGroupSummary) ([]string, []page.Row, map[string]lipgloss.Style) {
+	var taskGroups []string
+	for taskGroup := range summary {
+		taskGroups = append(taskGroups, taskGroup)
+	}
+	sort.Strings(taskGroups)
+
+	var taskGroupRows [][]string
+	rowStyles := make(map[string]lipgloss.Style)
+	for _, taskGroup := range taskGroups {
+		groupSummary := summary[taskGroup]
+		taskGroupRows = append(taskGroupRows, append([]string{taskGroup}, groupSummary.countColumns()...))
+		if healthStyle, ok := groupSummary.healthStyle(); ok {
+			rowStyles[taskGroup] = healthStyle
+		}
+	}
+
+	columns := append([]string{"Task Group"}, summaryColumns...)
+	tableHeader, rows := tableAsRows(columns, taskGroupRows, taskGroups, nil)
+
+	return tableHeader, rows, rowStyles
+}
+
+func TaskGroupFromKey(key string) string {
+	return key
+}
@@ -0,0 +1,165 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"wander/constants"
+	"wander/message"
+)
+
+// allocTaskSpecResponse is the part of GET /v1/allocation/:alloc_id that describes a task's templates and env
+// https://www.nomadproject.io/api-docs/allocations#read-allocation
+type allocTaskSpecResponse struct {
+	TaskGroup string `json:"TaskGroup"`
+	Job       struct {
+		TaskGroups []struct {
+			Name  string `json:"Name"`
+			Tasks []struct {
+				Name      string            `json:"Name"`
+				Env       map[string]string `json:"Env"`
+				Templates []taskTemplate    `json:"Templates"`
+			} `json:"Tasks"`
+		} `json:"TaskGroups"`
+	} `json:"Job"`
+}
+
+type taskTemplate struct {
+	SourcePath   string `json:"SourcePath"`
+	DestPath     string `json:"DestPath"`
+	EmbeddedTmpl string `json:"EmbeddedTmpl"`
+	ChangeMode   string `json:"ChangeMode"`
+	Envvars      bool   `json:"Envvars"`
+}
+
+func FetchTemplates(ctx context.Context, client *Client, allocID, taskName string) tea.Cmd {
+	return func() tea.Msg {
+		body, err := client.Allocation(ctx, allocID)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var allocResponse allocTaskSpecResponse
+		if err := json.Unmarshal(body, &allocResponse); err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		var templates []taskTemplate
+		env := make(map[string]string)
+		for _, taskGroup := range allocResponse.Job.TaskGroups {
+			if taskGroup.Name != allocResponse.TaskGroup {
+				continue
+			}
+			for _, task := range taskGroup.Tasks {
+				if task.Name == taskName {
+					templates = task.Templates
+					for k, v := range task.Env {
+						env[k] = v
+					}
+				}
+			}
+		}
+
+		var sections sectionsBuilder
+		for _, template := range templates {
+			filePath := templateFilePath(taskName, template.DestPath)
+			rendered, err := client.AllocFile(ctx, allocID, filePath, constants.MaxTemplateBytes)
+			if err != nil {
+				// e.g. files in the secrets directory can't be read through the API
+				sections.addSection(templateTitle(template, filePath), []string{fmt.Sprintf("Error reading rendered file: %s", err)})
+				continue
+			}
+			renderedLines := strings.Split(strings.TrimRight(string(rendered), "\n"), "\n")
+			sections.addSection(templateTitle(template, filePath), renderedLines)
+
+			if template.Envvars {
+				for k, v := range parseEnvFile(string(rendered)) {
+					env[k] = v
+				}
+			}
+		}
+
+		if len(templates) == 0 {
+			sections.addSection(fmt.Sprintf("No templates for %s", taskName), nil)
+		}
+
+		var envKeys []string
+		for k := range env {
+			envKeys = append(envKeys, k)
+		}
+		sort.Strings(envKeys)
+		var envLines []string
+		for _, k := range envKeys {
+			envLines = append(envLines, fmt.Sprintf("%s=%s", k, env[k]))
+		}
+		sections.addSection("Environment from job spec and env templates (excludes variables set by Nomad)", envLines)
+
+		return PageLoadedMsg{
+			Page:        TemplatesPage,
+			TableHeader: []string{},
+			AllPageData: sections.rows,
+		}
+	}
+}
+
+// templateFilePath returns where a template's destination is in the allocation's filesystem. Destinations are relative
+// to the task directory, and can start with the NOMAD_*_DIR variables, which are interpolated as they are in the task.
+func templateFilePath(taskName, destPath string) string {
+	taskDir := path.Join("/", taskName)
+	dirs := map[string]string{
+		"NOMAD_ALLOC_DIR":   "/alloc",
+		"NOMAD_TASK_DIR":    path.Join(taskDir, "local"),
+		"NOMAD_SECRETS_DIR": path.Join(taskDir, "secrets"),
+	}
+	expanded := os.Expand(destPath, func(name string) string {
+		if dir, ok := dirs[name]; ok {
+			return dir
+		}
+		return "${" + name + "}"
+	})
+	for _, dir := range dirs {
+		if expanded == dir || strings.HasPrefix(expanded, dir+"/") {
+			return path.Clean(expanded)
+		}
+	}
+	// the alloc directory is shared by the allocation's tasks rather than in the task directory
+	if expanded == "alloc" || strings.HasPrefix(expanded, "alloc/") {
+		return path.Join("/", expanded)
+	}
+	return path.Join(taskDir, expanded)
+}
+
+func templateTitle(template taskTemplate, filePath string) string {
+	source := "embedded"
+	if template.SourcePath != "" {
+		source = template.SourcePath
+	}
+	details := []string{fmt.Sprintf("source: %s", source)}
+	if template.ChangeMode != "" {
+		details = append(details, fmt.Sprintf("change mode: %s", template.ChangeMode))
+	}
+	if template.Envvars {
+		details = append(details, "env")
+	}
+	return fmt.Sprintf("%s (%s)", filePath, strings.Join(details, ", "))
+}
+
+// parseEnvFile parses KEY=VALUE lines as rendered by env templates, ignoring blank lines and comments
+func parseEnvFile(contents string) map[string]string {
+	env := make(map[string]string)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if split := strings.SplitN(line, "=", 2); len(split) == 2 {
+			env[strings.TrimSpace(split[0])] = strings.Trim(strings.TrimSpace(split[1]), `"'`)
+		}
+	}
+	return env
+}
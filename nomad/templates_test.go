@@ -0,0 +1,64 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFilePath(t *testing.T) {
+	tests := []struct {
+		destPath, expected string
+	}{
+		{"local/app.conf", "/web/local/app.conf"},
+		{"/local/app.conf", "/web/local/app.conf"},
+		{"${NOMAD_TASK_DIR}/app.conf", "/web/local/app.conf"},
+		{"$NOMAD_TASK_DIR/app.conf", "/web/local/app.conf"},
+		{"${NOMAD_SECRETS_DIR}/app.env", "/web/secrets/app.env"},
+		{"secrets/app.env", "/web/secrets/app.env"},
+		{"${NOMAD_ALLOC_DIR}/shared.conf", "/alloc/shared.conf"},
+		{"alloc/shared.conf", "/alloc/shared.conf"},
+		{"${OTHER}/app.conf", "/web/${OTHER}/app.conf"},
+	}
+	for _, test := range tests {
+		if actual := templateFilePath("web", test.destPath); actual != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.destPath, actual, test.expected)
+		}
+	}
+}
+
+// a template that can't be read shouldn't stop the others being shown
+func TestFetchTemplatesShowsReadErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/allocation/a1":
+			_, _ = w.Write([]byte(`{"TaskGroup": "g", "Job": {"TaskGroups": [{"Name": "g", "Tasks": [{"Name": "web", "Templates": [
+				{"DestPath": "${NOMAD_SECRETS_DIR}/app.env", "Envvars": true},
+				{"DestPath": "local/app.conf"}
+			]}]}]}}`))
+		case r.URL.Query().Get("path") == "/web/local/app.conf":
+			_, _ = w.Write([]byte("port = 80\n"))
+		default:
+			http.Error(w, "Reading secret file prohibited", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	msg := FetchTemplates(context.Background(), NewClient(server.URL, ""), "a1", "web")()
+	loaded, ok := msg.(PageLoadedMsg)
+	if !ok {
+		t.Fatalf("got %#v, expected the page to load", msg)
+	}
+	var lines []string
+	for _, row := range loaded.AllPageData {
+		lines = append(lines, row.Row)
+	}
+	page := strings.Join(lines, "\n")
+	for _, expected := range []string{"/web/secrets/app.env", "Reading secret file prohibited", "/web/local/app.conf", "port = 80"} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected %q in page:\n%s", expected, page)
+		}
+	}
+}
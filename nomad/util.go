@@ -1,32 +1,113 @@
 package nomad
 
 import (
-	"io/ioutil"
-	"net/http"
+	"fmt"
+	"strings"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
 )
 
-func get(url, token string, params map[string]string) ([]byte, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+// Table is a page's table as cells rather than rendered rows, with the API objects it was made from, for output
+// outside the TUI
+type Table struct {
+	Columns []string
+	Rows    [][]string
+	Objects interface{}
+}
+
+// bulkActionStatus reports the outcome of an action taken on each of names, where errs[i] is the error for names[i],
+// e.g. "Stopped 3 jobs", or the first failure and how many succeeded
+func bulkActionStatus(verb, noun string, names []string, errs []error) message.ActionStatusMsg {
+	succeeded := 0
+	var firstErr error
+	var failedName string
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if firstErr == nil {
+			firstErr, failedName = err, names[i]
+		}
+	}
+
+	if firstErr != nil {
+		if len(names) == 1 {
+			return message.ActionStatusMsg{Err: firstErr.Error()}
+		}
+		return message.ActionStatusMsg{Err: fmt.Sprintf(
+			"%s %d of %d %ss, %s failed: %v", strings.ToLower(verb), succeeded, len(names), noun, failedName, firstErr,
+		)}
+	}
+	if len(names) == 1 {
+		return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("%s %s %s", verb, noun, names[0])}
+	}
+	return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("%s %d %ss", verb, len(names), noun)}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
-	req.Header.Set("X-Nomad-Token", token)
+	return false
+}
+
+// SectionKey is the row key of section titles on pages made up of several sections
+const SectionKey = "section"
+
+// sectionsBuilder builds the rows of pages made up of several titled sections
+type sectionsBuilder struct {
+	rows []page.Row
+}
 
-	query := req.URL.Query()
-	for key, val := range params {
-		query.Add(key, val)
+func (b *sectionsBuilder) addSection(title string, lines []string) {
+	if len(b.rows) > 0 {
+		b.rows = append(b.rows, page.Row{Key: "", Row: ""})
 	}
-	req.URL.RawQuery = query.Encode()
+	b.rows = append(b.rows, page.Row{Key: SectionKey, Row: title})
+	for _, line := range lines {
+		b.rows = append(b.rows, page.Row{Key: "", Row: line})
+	}
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+func (b *sectionsBuilder) addTable(title string, columns []string, data [][]string) {
+	b.addTableWithKeys(title, columns, data, nil)
+}
+
+// addTableWithKeys adds a table section where each row of data has the corresponding key in keys
+func (b *sectionsBuilder) addTableWithKeys(title string, columns []string, data [][]string, keys []string) {
+	if len(data) == 0 {
+		b.addSection(title, []string{"none"})
+		return
 	}
+	header, rows := tableAsRows(columns, data, keys, nil)
+	b.addSection(title, header)
+	b.rows = append(b.rows, rows...)
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// tableAsRows renders a table, keeping each row's cells and the API object it was made from so the page can be saved
+// as structured data. Each row of data has the corresponding key in keys and object in objects, if any.
+func tableAsRows(columns []string, data [][]string, keys []string, objects []interface{}) ([]string, []page.Row) {
+	table := formatter.GetRenderedTableAsString(columns, data)
+	rows := make([]page.Row, 0, len(table.ContentRows))
+	for idx, row := range table.ContentRows {
+		rowData := &page.RowData{Columns: columns, Cells: data[idx]}
+		if idx < len(objects) {
+			rowData.Object = objects[idx]
+		}
+		key := ""
+		if idx < len(keys) {
+			key = keys[idx]
+		}
+		rows = append(rows, page.Row{Key: key, Row: row, Data: rowData})
 	}
-	return body, nil
+	return table.HeaderRows, rows
 }
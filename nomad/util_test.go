@@ -0,0 +1,26 @@
+package nomad
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBulkActionStatus(t *testing.T) {
+	failed := errors.New("permission denied")
+	tests := []struct {
+		names                        []string
+		errs                         []error
+		expectedSuccess, expectedErr string
+	}{
+		{[]string{"web"}, []error{nil}, "Stopped job web", ""},
+		{[]string{"web", "api"}, []error{nil, nil}, "Stopped 2 jobs", ""},
+		{[]string{"web"}, []error{failed}, "", "permission denied"},
+		{[]string{"web", "api", "db"}, []error{nil, failed, failed}, "", "stopped 1 of 3 jobs, api failed: permission denied"},
+	}
+	for _, test := range tests {
+		status := bulkActionStatus("Stopped", "job", test.names, test.errs)
+		if status.SuccessMessage != test.expectedSuccess || status.Err != test.expectedErr {
+			t.Errorf("%v: got %+v", test.names, status)
+		}
+	}
+}
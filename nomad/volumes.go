@@ -0,0 +1,213 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+	"strings"
+	"wander/components/page"
+	"wander/formatter"
+	"wander/message"
+)
+
+// volumeResponseEntry is returned from GET /v1/volumes?type=csi
+// https://www.nomadproject.io/api-docs/volumes#list-volumes
+type volumeResponseEntry struct {
+	ID                  string `json:"ID"`
+	Name                string `json:"Name"`
+	Namespace           string `json:"Namespace"`
+	PluginID            string `json:"PluginID"`
+	Provider            string `json:"Provider"`
+	AccessMode          string `json:"AccessMode"`
+	AttachmentMode      string `json:"AttachmentMode"`
+	Schedulable         bool   `json:"Schedulable"`
+	ControllerRequired  bool   `json:"ControllerRequired"`
+	ControllersHealthy  int    `json:"ControllersHealthy"`
+	ControllersExpected int    `json:"ControllersExpected"`
+	NodesHealthy        int    `json:"NodesHealthy"`
+	NodesExpected       int    `json:"NodesExpected"`
+}
+
+// volumeResponse is returned from GET /v1/volume/csi/:volume_id
+// https://www.nomadproject.io/api-docs/volumes#read-volume
+type volumeResponse struct {
+	volumeResponseEntry
+	ExternalID  string                     `json:"ExternalID"`
+	ReadAllocs  map[string]json.RawMessage `json:"ReadAllocs"`
+	WriteAllocs map[string]json.RawMessage `json:"WriteAllocs"`
+	Allocations []struct {
+		ID           string `json:"ID"`
+		JobID        string `json:"JobID"`
+		NodeID       string `json:"NodeID"`
+		TaskGroup    string `json:"TaskGroup"`
+		ClientStatus string `json:"ClientStatus"`
+	} `json:"Allocations"`
+}
+
+// CSIVolumes lists the CSI volumes in every namespace
+// https://www.nomadproject.io/api-docs/volumes#list-volumes
+func (c *Client) CSIVolumes(ctx context.Context) ([]volumeResponseEntry, error) {
+	var volumes []volumeResponseEntry
+	err := c.getJSON(ctx, "/v1/volumes", map[string]string{"type": "csi", "namespace": "*"}, &volumes)
+	return volumes, err
+}
+
+// CSIVolume reads a CSI volume
+// https://www.nomadproject.io/api-docs/volumes#read-volume
+func (c *Client) CSIVolume(ctx context.Context, volumeID, namespace string) (volumeResponse, error) {
+	var volume volumeResponse
+	err := c.getJSON(ctx, "/v1/volume/csi/"+volumeID, namespaceParams(namespace), &volume)
+	return volume, err
+}
+
+// DetachCSIVolume detaches a CSI volume from a node
+// https://www.nomadproject.io/api-docs/volumes#detach-volume
+func (c *Client) DetachCSIVolume(ctx context.Context, volumeID, namespace, nodeID string) error {
+	params := map[string]string{
+		"node":      nodeID,
+		"namespace": namespace,
+	}
+	_, err := c.del(ctx, "/v1/volume/csi/"+volumeID+"/detach", params)
+	return err
+}
+
+func FetchVolumes(ctx context.Context, client *Client) tea.Cmd {
+	return func() tea.Msg {
+		volumesResponse, err := client.CSIVolumes(ctx)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		sort.Slice(volumesResponse, func(x, y int) bool {
+			firstVolume := volumesResponse[x]
+			secondVolume := volumesResponse[y]
+			if firstVolume.ID == secondVolume.ID {
+				return firstVolume.Namespace < secondVolume.Namespace
+			}
+			return firstVolume.ID < secondVolume.ID
+		})
+
+		tableHeader, allPageData := volumesAsTable(volumesResponse)
+		return PageLoadedMsg{Page: VolumesPage, TableHeader: tableHeader, AllPageData: allPageData}
+	}
+}
+
+func FetchVolume(ctx context.Context, client *Client, volumeID, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		volume, err := client.CSIVolume(ctx, volumeID, namespace)
+		if err != nil {
+			return message.ErrMsg{Err: err}
+		}
+
+		return PageLoadedMsg{
+			Page:        VolumePage,
+			TableHeader: []string{},
+			AllPageData: volumeAsSections(volume),
+		}
+	}
+}
+
+func volumesAsTable(volumes []volumeResponseEntry) ([]string, []page.Row) {
+	var volumeRows [][]string
+	var keys []string
+	var objects []interface{}
+	for _, row := range volumes {
+		volumeRows = append(volumeRows, []string{
+			row.ID,
+			row.Name,
+			row.Namespace,
+			row.PluginID,
+			row.AccessMode,
+			row.AttachmentMode,
+			strconv.FormatBool(row.Schedulable),
+			fmt.Sprintf("%d/%d", row.ControllersHealthy, row.ControllersExpected),
+			fmt.Sprintf("%d/%d", row.NodesHealthy, row.NodesExpected),
+		})
+		keys = append(keys, toVolumesKey(row))
+		objects = append(objects, row)
+	}
+
+	columns := []string{"ID", "Name", "Namespace", "Plugin", "Access Mode", "Attachment Mode", "Schedulable", "Controllers Healthy", "Nodes Healthy"}
+	tableHeader, rows := tableAsRows(columns, volumeRows, keys, objects)
+
+	return tableHeader, rows
+}
+
+func volumeAsSections(volume volumeResponse) []page.Row {
+	var sections sectionsBuilder
+
+	sections.addTable("Volume", []string{"Field", "Value"}, [][]string{
+		{"ID", volume.ID},
+		{"Name", volume.Name},
+		{"External ID", volume.ExternalID},
+		{"Namespace", volume.Namespace},
+		{"Plugin", fmt.Sprintf("%s (%s)", volume.PluginID, volume.Provider)},
+		{"Access Mode", volume.AccessMode},
+		{"Attachment Mode", volume.AttachmentMode},
+		{"Schedulable", strconv.FormatBool(volume.Schedulable)},
+		{"Controllers Healthy", fmt.Sprintf("%d/%d", volume.ControllersHealthy, volume.ControllersExpected)},
+		{"Nodes Healthy", fmt.Sprintf("%d/%d", volume.NodesHealthy, volume.NodesExpected)},
+	})
+
+	var claimRows [][]string
+	var claimKeys []string
+	for _, alloc := range volume.Allocations {
+		var modes []string
+		if _, exists := volume.ReadAllocs[alloc.ID]; exists {
+			modes = append(modes, "read")
+		}
+		if _, exists := volume.WriteAllocs[alloc.ID]; exists {
+			modes = append(modes, "write")
+		}
+		claimRows = append(claimRows, []string{
+			formatter.ShortAllocID(alloc.ID),
+			alloc.JobID,
+			alloc.TaskGroup,
+			formatter.ShortAllocID(alloc.NodeID),
+			strings.Join(modes, "/"),
+			alloc.ClientStatus,
+		})
+		claimKeys = append(claimKeys, toVolumeClaimKey(alloc.NodeID, alloc.ID))
+	}
+	sections.addTableWithKeys("Claims", []string{"Alloc ID", "Job", "Task Group", "Node", "Mode", "Client Status"}, claimRows, claimKeys)
+
+	return sections.rows
+}
+
+// DetachVolume detaches a CSI volume from a node, releasing the claims of allocations on that node
+func DetachVolume(ctx context.Context, client *Client, volumeID, namespace, nodeID string) tea.Cmd {
+	return func() tea.Msg {
+		if err := client.DetachCSIVolume(ctx, volumeID, namespace, nodeID); err != nil {
+			return message.ActionStatusMsg{Err: err.Error()}
+		}
+		return message.ActionStatusMsg{SuccessMessage: fmt.Sprintf("Detached volume %s from node %s", volumeID, formatter.ShortAllocID(nodeID))}
+	}
+}
+
+func toVolumesKey(volumeResponseEntry volumeResponseEntry) string {
+	return strings.Join([]string{volumeResponseEntry.Namespace, volumeResponseEntry.ID, volumeResponseEntry.PluginID}, " ")
+}
+
+func VolumeIDNamespaceAndPluginIDFromKey(key string) (string, string, string) {
+	split := strings.Split(key, " ")
+	return split[1], split[0], split[2]
+}
+
+const volumeClaimKeyPrefix = "claim"
+
+func toVolumeClaimKey(nodeID, allocID string) string {
+	return strings.Join([]string{volumeClaimKeyPrefix, nodeID, allocID}, " ")
+}
+
+// NodeIDAndAllocIDFromVolumeClaimKey returns the node and allocation of a volume claim row. Returns false if the key
+// is not that of a claim row.
+func NodeIDAndAllocIDFromVolumeClaimKey(key string) (string, string, bool) {
+	split := strings.Split(key, " ")
+	if len(split) != 3 || split[0] != volumeClaimKeyPrefix {
+		return "", "", false
+	}
+	return split[1], split[2], true
+}
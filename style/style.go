@@ -4,16 +4,116 @@ import "github.com/charmbracelet/lipgloss"
 
 var (
 	Bold                = lipgloss.NewStyle().Bold(true)
-	Logo                = lipgloss.NewStyle().MarginBottom(1).Padding(0).Foreground(lipgloss.Color("#dbbd70"))
+	Logo                lipgloss.Style
 	ClusterUrl          = lipgloss.NewStyle()
 	KeyHelp             = lipgloss.NewStyle().Padding(0, 2)
-	KeyHelpKey          = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
-	KeyHelpDescription  = lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
+	KeyHelpKey          lipgloss.Style
+	KeyHelpDescription  lipgloss.Style
 	Header              = lipgloss.NewStyle().Padding(0, 1).Border(lipgloss.RoundedBorder(), true)
-	Viewport            = lipgloss.NewStyle().Background(lipgloss.Color("#000000"))
+	Viewport            lipgloss.Style
 	ViewportHeaderStyle = lipgloss.NewStyle().Bold(true)
-	StdOut              = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
-	StdErr              = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5353"))
-	SuccessToast        = lipgloss.NewStyle().Bold(true).PaddingLeft(1).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#00FF00"))
-	ErrorToast          = lipgloss.NewStyle().Bold(true).PaddingLeft(1).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#FF0000"))
+	StdOut              lipgloss.Style
+	StdErr              lipgloss.Style
+	Warning             lipgloss.Style
+	Healthy             lipgloss.Style
+	Degraded            lipgloss.Style
+	Unhealthy           lipgloss.Style
+	SuccessToast        lipgloss.Style
+	ErrorToast          lipgloss.Style
+
+	// styles of components, read when they're created
+	CursorRow     lipgloss.Style
+	MarkedRow     lipgloss.Style
+	Highlight     lipgloss.Style
+	Footer        lipgloss.Style
+	Dialog        lipgloss.Style
+	FilterPrefix  lipgloss.Style
+	Filter        lipgloss.Style
+	AppliedFilter lipgloss.Style
+	EditingFilter lipgloss.Style
 )
+
+func init() {
+	SetTheme(Dark)
+}
+
+// SetTheme sets the styles to use theme's colors. Components created before aren't restyled.
+func SetTheme(theme Theme) {
+	Logo = logo().Foreground(color(theme.Logo))
+	KeyHelpKey = lipgloss.NewStyle().Foreground(color(theme.HelpKey)).Bold(true)
+	KeyHelpDescription = lipgloss.NewStyle().Foreground(color(theme.HelpDescription))
+	Viewport = lipgloss.NewStyle().Background(color(theme.Background))
+	StdOut = lipgloss.NewStyle().Foreground(color(theme.Text))
+	StdErr = lipgloss.NewStyle().Foreground(color(theme.Error))
+	Warning = lipgloss.NewStyle().Foreground(color(theme.Error)).Bold(true)
+	Healthy = lipgloss.NewStyle().Foreground(color(theme.Healthy))
+	Degraded = lipgloss.NewStyle().Foreground(color(theme.Degraded))
+	Unhealthy = lipgloss.NewStyle().Foreground(color(theme.Error))
+	SuccessToast = toast().Foreground(color(theme.SelectedText)).Background(color(theme.SuccessToast))
+	ErrorToast = toast().Foreground(color(theme.SelectedText)).Background(color(theme.ErrorToast))
+
+	CursorRow = lipgloss.NewStyle().Foreground(color(theme.SelectedText)).Background(color(theme.Cursor))
+	MarkedRow = lipgloss.NewStyle().Foreground(color(theme.SelectedText)).Background(color(theme.Marked))
+	Highlight = lipgloss.NewStyle().Foreground(color(theme.SelectedText)).Background(color(theme.Highlight))
+	Footer = lipgloss.NewStyle().Foreground(color(theme.Footer))
+	Dialog = lipgloss.NewStyle().Background(color(theme.Dialog)).Foreground(color(theme.SelectedText))
+	FilterPrefix = filterPrefix().Foreground(color(theme.FilterPrefix))
+	Filter = filter().Foreground(color(theme.Filter))
+	AppliedFilter = filterBox().Foreground(color(theme.SelectedText)).Background(color(theme.AppliedFilter))
+	EditingFilter = filterBox().Foreground(color(theme.SelectedText)).Background(color(theme.EditingFilter))
+}
+
+// SetMonochrome sets the styles to use no colors, showing the cursor, marks, highlights and dialogs with reverse
+// video, bold and underline instead
+func SetMonochrome() {
+	Logo = logo()
+	KeyHelpKey = lipgloss.NewStyle().Bold(true)
+	KeyHelpDescription = lipgloss.NewStyle()
+	Viewport = lipgloss.NewStyle()
+	StdOut = lipgloss.NewStyle()
+	StdErr = lipgloss.NewStyle().Bold(true)
+	Warning = lipgloss.NewStyle().Bold(true)
+	Healthy = lipgloss.NewStyle()
+	Degraded = lipgloss.NewStyle().Underline(true)
+	Unhealthy = lipgloss.NewStyle().Bold(true)
+	SuccessToast = toast().Reverse(true)
+	ErrorToast = toast().Reverse(true)
+
+	CursorRow = lipgloss.NewStyle().Reverse(true)
+	MarkedRow = lipgloss.NewStyle().Underline(true)
+	Highlight = lipgloss.NewStyle().Bold(true).Underline(true)
+	Footer = lipgloss.NewStyle()
+	Dialog = lipgloss.NewStyle().Reverse(true)
+	FilterPrefix = filterPrefix()
+	Filter = filter()
+	AppliedFilter = filterBox().Reverse(true)
+	EditingFilter = filterBox().Reverse(true).Bold(true)
+}
+
+// color returns a lipgloss color, or no color for the terminal's default if c is empty
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
+
+func logo() lipgloss.Style {
+	return lipgloss.NewStyle().MarginBottom(1).Padding(0)
+}
+
+func toast() lipgloss.Style {
+	return lipgloss.NewStyle().Bold(true).PaddingLeft(1)
+}
+
+func filterPrefix() lipgloss.Style {
+	return lipgloss.NewStyle().Padding(0, 3).Border(lipgloss.NormalBorder(), true)
+}
+
+func filter() lipgloss.Style {
+	return lipgloss.NewStyle().Margin(0, 1)
+}
+
+func filterBox() lipgloss.Style {
+	return lipgloss.NewStyle().Margin(0, 1).Padding(0, 1)
+}
@@ -0,0 +1,125 @@
+package style
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Theme is the colors of the app. Each is a hex color like "#FF5353", an ANSI color number like "6", or empty for
+// the terminal's default.
+type Theme struct {
+	// Text is the color of logs and specs
+	Text string
+	// Background is the background of pages
+	Background      string
+	Logo            string
+	HelpKey         string
+	HelpDescription string
+	// Cursor, Marked and Highlight are the backgrounds of the selected row, marked rows and text matching the filter
+	Cursor    string
+	Marked    string
+	Highlight string
+	// SelectedText is the color of text on the cursor, marks, highlights, toasts, dialogs and the filter
+	SelectedText string
+	Footer       string
+	// Error is the color of stderr, warnings and unhealthy task groups
+	Error    string
+	Healthy  string
+	Degraded string
+	// Dialog is the background of the save and jump dialogs and prompts
+	Dialog        string
+	ErrorToast    string
+	SuccessToast  string
+	FilterPrefix  string
+	Filter        string
+	AppliedFilter string
+	EditingFilter string
+}
+
+var Dark = Theme{
+	Text:            "#FFFFFF",
+	Background:      "#000000",
+	Logo:            "#dbbd70",
+	HelpKey:         "6",
+	HelpDescription: "7",
+	Cursor:          "6",
+	Marked:          "#737373",
+	Highlight:       "#e760fc",
+	SelectedText:    "#000000",
+	Footer:          "#737373",
+	Error:           "#FF5353",
+	Healthy:         "#00FF00",
+	Degraded:        "#FFD700",
+	Dialog:          "#FF0000",
+	ErrorToast:      "#FF0000",
+	SuccessToast:    "#00FF00",
+	FilterPrefix:    "#FFFFFF",
+	Filter:          "#8E8E8E",
+	AppliedFilter:   "#00A095",
+	EditingFilter:   "6",
+}
+
+// Light is for terminals with light backgrounds, leaving text and backgrounds as the terminal's
+var Light = Theme{
+	Logo:            "#9A6700",
+	HelpKey:         "#005F87",
+	HelpDescription: "#4E4E4E",
+	Cursor:          "#87D7FF",
+	Marked:          "#BCBCBC",
+	Highlight:       "#F5A3FF",
+	SelectedText:    "#000000",
+	Footer:          "#6C6C6C",
+	Error:           "#C00000",
+	Healthy:         "#007A00",
+	Degraded:        "#946B00",
+	Dialog:          "#FF8787",
+	ErrorToast:      "#FF8787",
+	SuccessToast:    "#87D787",
+	Filter:          "#6C6C6C",
+	AppliedFilter:   "#5FD7AF",
+	EditingFilter:   "#87D7FF",
+}
+
+// HighContrast uses pure colors on black
+var HighContrast = Theme{
+	Text:            "#FFFFFF",
+	Background:      "#000000",
+	Logo:            "#FFFF00",
+	HelpKey:         "#00FFFF",
+	HelpDescription: "#FFFFFF",
+	Cursor:          "#FFFF00",
+	Marked:          "#00FFFF",
+	Highlight:       "#FF00FF",
+	SelectedText:    "#000000",
+	Footer:          "#FFFFFF",
+	Error:           "#FF0000",
+	Healthy:         "#00FF00",
+	Degraded:        "#FFFF00",
+	Dialog:          "#FFFF00",
+	ErrorToast:      "#FF0000",
+	SuccessToast:    "#00FF00",
+	FilterPrefix:    "#FFFFFF",
+	Filter:          "#FFFFFF",
+	AppliedFilter:   "#00FF00",
+	EditingFilter:   "#FFFF00",
+}
+
+// Themes are the built-in themes by name
+var Themes = map[string]Theme{
+	"dark":          Dark,
+	"light":         Light,
+	"high-contrast": HighContrast,
+}
+
+// WithColors returns the theme with the colors named by field in colors changed, e.g. {"Cursor": "#00AFFF"}
+func (t Theme) WithColors(colors map[string]string) (Theme, error) {
+	v := reflect.ValueOf(&t).Elem()
+	for name, c := range colors {
+		field := v.FieldByName(name)
+		if !field.IsValid() {
+			return t, fmt.Errorf("unknown theme color %q", name)
+		}
+		field.SetString(c)
+	}
+	return t, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"wander/config"
+	"wander/style"
+)
+
+// monochromeTheme is the built-in theme without colors, also used if NO_COLOR is set or the terminal has no colors
+const monochromeTheme = "monochrome"
+
+// applyThemeConfig sets the styles to the configured theme, or to dark or light to suit the terminal's background if
+// none is configured. If noColor, the styles are monochrome whatever the theme.
+func applyThemeConfig(cfg config.Config, noColor bool, hasDarkBackground func() bool) error {
+	for name := range cfg.Themes {
+		if _, err := themeNamed(name, cfg.Themes); err != nil {
+			return err
+		}
+	}
+
+	name := cfg.Theme
+	if name == "" {
+		name = monochromeTheme
+		if !noColor {
+			name = "light"
+			if hasDarkBackground() {
+				name = "dark"
+			}
+		}
+	}
+
+	var theme style.Theme
+	if name != monochromeTheme {
+		var err error
+		if theme, err = themeNamed(name, cfg.Themes); err != nil {
+			return err
+		}
+	}
+	if noColor || name == monochromeTheme {
+		style.SetMonochrome()
+	} else {
+		style.SetTheme(theme)
+	}
+	return nil
+}
+
+// themeNamed returns the user theme called name, or the built-in one if there's no user theme called name
+func themeNamed(name string, userThemes map[string]config.Theme) (style.Theme, error) {
+	userTheme, isUserTheme := userThemes[name]
+	if !isUserTheme {
+		if theme, exists := style.Themes[name]; exists {
+			return theme, nil
+		}
+		var names []string
+		for builtIn := range style.Themes {
+			names = append(names, builtIn)
+		}
+		for user := range userThemes {
+			names = append(names, user)
+		}
+		names = append(names, monochromeTheme)
+		sort.Strings(names)
+		return style.Theme{}, fmt.Errorf("unknown theme %q, must be one of: %s", name, strings.Join(names, ", "))
+	}
+
+	baseName := userTheme.Base
+	if baseName == "" {
+		baseName = "dark"
+	}
+	base, exists := style.Themes[baseName]
+	if !exists {
+		return style.Theme{}, fmt.Errorf("theme %s has unknown base %q", name, baseName)
+	}
+	theme, err := base.WithColors(userTheme.Colors)
+	if err != nil {
+		return style.Theme{}, fmt.Errorf("theme %s: %w", name, err)
+	}
+	return theme, nil
+}
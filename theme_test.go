@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+	"testing"
+	"wander/config"
+	"wander/nomad/nomadtest"
+	"wander/style"
+)
+
+// applyTestTheme applies the theme config until the test ends
+func applyTestTheme(t *testing.T, cfg config.Config, noColor, darkBackground bool) error {
+	t.Cleanup(func() { style.SetTheme(style.Dark) })
+	return applyThemeConfig(cfg, noColor, func() bool { return darkBackground })
+}
+
+func TestThemeForBackground(t *testing.T) {
+	if err := applyTestTheme(t, config.Config{}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, isDefault := style.StdOut.GetForeground().(lipgloss.NoColor); !isDefault {
+		t.Errorf("expected light theme to leave text the terminal's color, got %v", style.StdOut.GetForeground())
+	}
+
+	if err := applyTestTheme(t, config.Config{}, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if actual := style.StdOut.GetForeground(); actual != lipgloss.Color(style.Dark.Text) {
+		t.Errorf("expected dark theme, got text color %v", actual)
+	}
+}
+
+func TestUserTheme(t *testing.T) {
+	cfg := config.Config{
+		Theme:  "mine",
+		Themes: map[string]config.Theme{"mine": {Base: "high-contrast", Colors: map[string]string{"Cursor": "#00AFFF"}}},
+	}
+	if err := applyTestTheme(t, cfg, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if actual := style.CursorRow.GetBackground(); actual != lipgloss.Color("#00AFFF") {
+		t.Errorf("expected cursor color from user theme, got %v", actual)
+	}
+	if actual := style.Highlight.GetBackground(); actual != lipgloss.Color(style.HighContrast.Highlight) {
+		t.Errorf("expected other colors from base theme, got %v", actual)
+	}
+}
+
+func TestThemeErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		cfg      config.Config
+		expected string
+	}{
+		"unknown theme": {config.Config{Theme: "solarized"}, `unknown theme "solarized"`},
+		"unknown base": {
+			config.Config{Themes: map[string]config.Theme{"mine": {Base: "solarized"}}},
+			`theme mine has unknown base "solarized"`,
+		},
+		"unknown color": {
+			config.Config{Themes: map[string]config.Theme{"mine": {Colors: map[string]string{"Cursorr": "1"}}}},
+			`unknown theme color "Cursorr"`,
+		},
+	} {
+		// invalid themes are errors even when colors are off
+		err := applyTestTheme(t, test.cfg, true, true)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error containing %s, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	server := nomadtest.NewServer()
+	defer server.Close()
+	if err := applyTestTheme(t, config.Config{Theme: "light"}, true, true); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newHarness(t, server)
+	h.keys("/", "w")
+	view := h.model.View()
+	if strings.Contains(view, "\x1b[38;") || strings.Contains(view, "\x1b[48;") {
+		t.Errorf("expected no colors, got %q", view)
+	}
+	// reverse video shows the cursor, bold and underline the text matching the filter
+	for _, expected := range []string{"\x1b[7m", "\x1b[1;4"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q in view %q", expected, view)
+		}
+	}
+}